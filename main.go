@@ -3,34 +3,91 @@ package main
 import (
 	_ "embed"
 	"fmt"
-	"log"
+	"os"
 	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/rhydianjenkins/seek/src/config"
 	"github.com/rhydianjenkins/seek/src/handlers"
+	"github.com/rhydianjenkins/seek/src/logging"
 	"github.com/rhydianjenkins/seek/src/mcp"
+	"github.com/rhydianjenkins/seek/src/services"
 	"github.com/spf13/cobra"
 )
 
 //go:embed VERSION
 var version string
-var logfile = "seek.log"
+
+// skipConfig marks commands that must work without a valid config, such as those that fix it
+const skipConfig = "skip-config"
+
+func needsConfig(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[skipConfig] == "true" {
+			return false
+		}
+	}
+	return true
+}
 
 func initCmd() *cobra.Command {
+	var logOpts logging.Options
+	var outputFlag string
+	var output handlers.OutputFormat
+	var configOpts config.LoadOptions
+	var kbs []string
 	var rootCmd = &cobra.Command{
 		Use:   "seek",
 		Short: "Knowledge base search engine",
 		Long:  "Seek is a knowledge base search engine that uses AI to answer questions about the indexed documents.",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			config.Initialize(&config.Config{
-				ServerVersion: strings.TrimSpace(version),
-			})
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := logging.Configure(logOpts); err != nil {
+				return err
+			}
+
+			var err error
+			if output, err = handlers.ParseOutputFormat(outputFlag); err != nil {
+				return err
+			}
+
+			// Flags and arguments are valid by now, so later errors should not print usage
+			cmd.SilenceUsage = true
+
+			if !needsConfig(cmd) {
+				return nil
+			}
+
+			// Only search can merge several knowledge bases; everything else works on one
+			if len(kbs) > 1 && cmd.Name() != "search" {
+				return fmt.Errorf("%w: seek %s takes a single --kb", services.ErrInvalidInput, cmd.Name())
+			}
+			if len(kbs) > 0 {
+				configOpts.Flags.Collection = kbs[0]
+			}
+
+			cfg, err := config.Load(configOpts)
+			if err != nil {
+				return err
+			}
+			cfg.ServerVersion = strings.TrimSpace(version)
+			config.Initialize(cfg)
+			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Help()
 		},
 	}
+	rootCmd.PersistentFlags().StringVar(&configOpts.ConfigFile, "config", "", "Config file to use instead of looking up seek.yaml (also SEEK_CONFIG)")
+	rootCmd.PersistentFlags().StringVar(&configOpts.Profile, "profile", "", "Config profile to use (also SEEK_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&configOpts.Flags.OllamaURL, "ollama-url", "", "Ollama URL, overriding the config and environment")
+	rootCmd.PersistentFlags().StringVar(&configOpts.Flags.QdrantHost, "qdrant-host", "", "Qdrant host, overriding the config and environment")
+	rootCmd.PersistentFlags().IntVar(&configOpts.Flags.QdrantPort, "qdrant-port", 0, "Qdrant gRPC port, overriding the config and environment")
+	rootCmd.PersistentFlags().StringVar(&configOpts.Flags.ChatModel, "chat-model", "", "Ollama chat model, overriding the config and environment")
+	rootCmd.PersistentFlags().StringSliceVar(&kbs, "kb", nil, "Knowledge base (Qdrant collection) to use instead of the configured one; search accepts several, e.g. --kb docs,wiki")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", string(handlers.OutputText), "Output format: text, json, jsonl (one record per line) or yaml")
+	rootCmd.PersistentFlags().StringVar(&logOpts.Level, "log-level", "info", "Minimum level to log: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logOpts.Format, "log-format", logging.FormatText, "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logOpts.File, "log-file", "", "Append logs to this file instead of stderr (seek mcp over stdio defaults to $XDG_STATE_HOME/seek/seek.log)")
 
 	var dataDir string
 	var chunkSize int
@@ -41,8 +98,8 @@ func initCmd() *cobra.Command {
 		Example: `  seek embed --dataDir ./documents
   seek embed --dataDir ./docs --chunkSize 500`,
 		Args: cobra.ExactArgs(0),
-		Run: func(cmd *cobra.Command, args []string) {
-			handlers.Embed(dataDir, chunkSize)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.Embed(dataDir, chunkSize, output)
 		},
 	}
 	embedCmd.Flags().StringVar(&dataDir, "dataDir", "", "Directory containing .txt files to embed (required)")
@@ -50,97 +107,389 @@ func initCmd() *cobra.Command {
 	embedCmd.MarkFlagRequired("dataDir")
 	rootCmd.AddCommand(embedCmd)
 
+	var askJSON bool
+	var askSession string
 	var askCmd = &cobra.Command{
 		Use:   "ask <question>",
 		Short: "Ask a question about the knowledge base",
 		Long:  "Ask a natural language question and get answers based on your indexed documents. The AI will search the knowledge base and provide relevant information.",
 		Example: `  seek ask "What is the company culture?"
-  seek ask "How does authentication work?"`,
+  seek ask "How does authentication work?"
+  seek ask "What is the company culture?" --output json
+  seek ask "And how is it measured?" --session culture`,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			question := args[0]
-			err := handlers.AskQuestion(question)
-
-			if err != nil {
-				log.Println("Error:", err)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if askJSON {
+				output = handlers.OutputJSON
 			}
+			return handlers.AskQuestion(args[0], output, askSession)
 		},
 	}
+	askCmd.Flags().BoolVar(&askJSON, "json", false, "Print the answer and its citations as JSON")
+	askCmd.Flags().MarkDeprecated("json", "use --output json instead")
+	askCmd.Flags().StringVar(&askSession, "session", "", "Save the conversation to the named session, resuming it if it exists")
 	rootCmd.AddCommand(askCmd)
 
-	var limit int
+	var chatSession string
+	var chatCmd = &cobra.Command{
+		Use:   "chat",
+		Short: "Have a conversation with the knowledge base",
+		Long:  "Start an interactive conversation with the knowledge base. The history is saved to a session after every answer so it can be resumed later.",
+		Example: `  seek chat
+  seek chat --session onboarding`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.Chat(chatSession)
+		},
+	}
+	chatCmd.Flags().StringVar(&chatSession, "session", "", "Name of the session to start or resume (default: a timestamped name)")
+	rootCmd.AddCommand(chatCmd)
+
+	var sessionCmd = &cobra.Command{
+		Use:   "session",
+		Short: "Manage saved conversation sessions",
+		Long:  "List, delete and export the conversation sessions saved by 'seek ask --session' and 'seek chat'.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	sessionCmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Short:   "List saved sessions",
+		Example: `  seek session list`,
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.ListSessions(output)
+		},
+	})
+
+	sessionCmd.AddCommand(&cobra.Command{
+		Use:     "delete <name>",
+		Short:   "Delete a saved session",
+		Example: `  seek session delete onboarding`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.DeleteSession(args[0])
+		},
+	})
+
+	var exportFile string
+	var sessionExportCmd = &cobra.Command{
+		Use:   "export <name>",
+		Short: "Export a session to Markdown",
+		Example: `  seek session export onboarding
+  seek session export onboarding --file onboarding.md`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.ExportSession(args[0], exportFile)
+		},
+	}
+	sessionExportCmd.Flags().StringVar(&exportFile, "file", "", "Write the Markdown to this file instead of stdout")
+	sessionCmd.AddCommand(sessionExportCmd)
+	rootCmd.AddCommand(sessionCmd)
+
+	var searchOpts services.SearchOptions
+	var expand string
+	var groupBy string
 	var searchCmd = &cobra.Command{
 		Use:   "search <query>",
 		Short: "Search the knowledge base",
 		Long:  "Perform semantic search on the indexed documents using embeddings. Returns the most relevant chunks of text based on similarity.",
 		Example: `  seek search "authentication"
-  seek search "company culture" --limit 5`,
+  seek search "company culture" --limit 5
+  seek search "company culture" --output jsonl | jq .filename
+  seek search "deploys" --kb docs,wiki
+  seek search "onboarding" --limit 5 --max-per-doc 1
+  seek search "onboarding" --limit 5 --diversity 0.3
+  seek search "rollback steps" --context 2
+  seek search "rollback steps" --min-score 0.6 --limit 10 --offset 10
+  seek search "rate limits" --expand
+  seek search "rate limits" --expand hyde
+  seek search "OAuth" --group-by file`,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			searchTerm := args[0]
-			handlers.Search(searchTerm, limit)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			searchOpts.Query = args[0]
+			searchOpts.Expand = services.ExpandMode(expand)
+			searchOpts.GroupBy = services.GroupBy(groupBy)
+			if len(kbs) > 1 {
+				searchOpts.KBs = kbs
+			}
+			return handlers.Search(searchOpts, output)
 		},
 	}
-	searchCmd.Flags().IntVar(&limit, "limit", 3, "Maximum number of search results to return")
+	searchCmd.Flags().IntVar(&searchOpts.Limit, "limit", 3, "Maximum number of search results to return")
+	searchCmd.Flags().IntVar(&searchOpts.Offset, "offset", 0, "Number of results to skip, for paging")
+	searchCmd.Flags().Float32Var(&searchOpts.MinScore, "min-score", 0, "Drop results whose similarity score is below this (between 0 and 1)")
+	searchCmd.Flags().Float64Var(&searchOpts.Diversity, "diversity", 0, "Between 0 and 1: prefer results that differ from each other over the most similar ones (Maximal Marginal Relevance)")
+	searchCmd.Flags().IntVar(&searchOpts.MaxPerDoc, "max-per-doc", 0, "Maximum number of results from any one document (0 for no cap)")
+	searchCmd.Flags().StringVar(&expand, "expand", "", "Also search for texts written by the chat model and fuse the rankings: paraphrase (the default when given without a value) or hyde (a hypothetical answer)")
+	searchCmd.Flags().Lookup("expand").NoOptDefVal = string(services.ExpandParaphrase)
+	searchCmd.Flags().StringVar(&groupBy, "group-by", "", "Set to file to return the best matching documents, each with its closest chunks, instead of chunks")
+	searchCmd.Flags().IntVar(&searchOpts.Context, "context", 0, "Include this many neighbouring chunks either side of each result, merging overlapping results")
 	rootCmd.AddCommand(searchCmd)
 
+	var grepOpts services.GrepOptions
+	var grepCmd = &cobra.Command{
+		Use:   "grep <pattern>",
+		Short: "Find lines matching a regular expression",
+		Long:  "Search the indexed text, including text extracted from PDF, Word and Excel files, for lines matching a regular expression (Go syntax). Prints the file, chunk and line within the chunk of each match, stopping after --limit matches.",
+		Example: `  seek grep ERR_4091
+  seek grep -w -i "connection reset"
+  seek grep "timeout after [0-9]+s" --limit 20`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			grepOpts.Pattern = args[0]
+			return handlers.Grep(grepOpts, output)
+		},
+	}
+	grepCmd.Flags().BoolVarP(&grepOpts.IgnoreCase, "ignore-case", "i", false, "Ignore case when matching")
+	grepCmd.Flags().BoolVarP(&grepOpts.Word, "word-regexp", "w", false, "Only match whole words (also lets the full-text index find candidates faster)")
+	grepCmd.Flags().IntVar(&grepOpts.Limit, "limit", 100, "Maximum number of matching lines to print; grep stops there, so with more matches they are the first found rather than the first by filename")
+	rootCmd.AddCommand(grepCmd)
+
+	var similarOpts services.SimilarOptions
+	var similarChunk int64
+	var similarCmd = &cobra.Command{
+		Use:   "similar <filename>",
+		Short: "Find documents similar to a document",
+		Long:  "Find the documents most like an indexed document, or one of its chunks, using the vectors already stored for it. The document itself is left out, and each result shows its closest chunks.",
+		Example: `  seek similar "emails/2024-03-offsite.txt"
+  seek similar "specs/auth.md" --chunk 4 --limit 10`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			similarOpts.Filename = args[0]
+			if cmd.Flags().Changed("chunk") {
+				similarOpts.Chunk = &similarChunk
+			}
+			return handlers.FindSimilar(similarOpts, output)
+		},
+	}
+	similarCmd.Flags().Int64Var(&similarChunk, "chunk", 0, "Only use this chunk of the document (default: the whole document)")
+	similarCmd.Flags().IntVar(&similarOpts.Limit, "limit", 5, "Maximum number of documents to return")
+	rootCmd.AddCommand(similarCmd)
+
+	var getFrom, getTo int64
 	var getCmd = &cobra.Command{
 		Use:   "get <filename>",
 		Short: "Get a full document by filename",
-		Long:  "Retrieve and display the complete contents of a document from the knowledge base by its filename. All chunks are reassembled in order.",
+		Long:  "Retrieve and display the complete contents of a document from the knowledge base by its filename. All chunks are reassembled in order and printed as they are read, or only those from --from to --to. When there is no such document, the closest filenames are suggested.",
 		Example: `  seek get "README.md"
-  seek get "docs/architecture.txt"`,
+  seek get "docs/architecture.txt"
+  seek get "manuals/big.pdf" --from 100 --to 199`,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			filename := args[0]
-			handlers.GetDocument(filename)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := services.DocumentOptions{Filename: args[0]}
+			if cmd.Flags().Changed("from") {
+				opts.From = &getFrom
+			}
+			if cmd.Flags().Changed("to") {
+				opts.To = &getTo
+			}
+			return handlers.GetDocument(opts, output)
 		},
 	}
+	getCmd.Flags().Int64Var(&getFrom, "from", 0, "First chunk index to print")
+	getCmd.Flags().Int64Var(&getTo, "to", 0, "Last chunk index to print, inclusive")
 	rootCmd.AddCommand(getCmd)
 
 	var statusCmd = &cobra.Command{
-		Use:     "status",
-		Short:   "Show the status of the database",
-		Long:    "Display information about the Qdrant vector database including whether the collection exists, how many vectors are stored, and collection configuration.",
-		Example: `  seek status`,
-		Args:    cobra.ExactArgs(0),
-		Run: func(cmd *cobra.Command, args []string) {
-			handlers.Status()
+		Use:   "status",
+		Short: "Show the status of the database",
+		Long:  "Display information about the Qdrant vector database including whether the collection exists, how many vectors are stored, and collection configuration. Unlike other commands, status prints JSON unless --output says otherwise, as it always has.",
+		Example: `  seek status
+  seek status --output text`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Scripts parse status output, which was JSON before there was --output
+			if !cmd.Flags().Changed("output") {
+				return handlers.Status(handlers.OutputJSON)
+			}
+			return handlers.Status(output)
 		},
 	}
 	rootCmd.AddCommand(statusCmd)
 
-	var listLimit int
+	var listOpts services.ListOptions
+	var listLong bool
+	var listSort string
 	var listCmd = &cobra.Command{
 		Use:   "list",
 		Short: "List all document names in the database",
-		Long:  "Display all unique document filenames that have been indexed in the knowledge base.",
+		Long:  "Display the documents that have been indexed in the knowledge base, optionally filtered by prefix, glob or file type, sorted, and paged with --offset/--limit. Documents are read from the knowledge base's manifest, so listing stays fast however many chunks there are; --long shows the manifest as a table.",
 		Example: `  seek list
-  seek list --limit 50`,
+  seek list --limit 50 --offset 50
+  seek list --prefix emails/ --long
+  seek list --pattern "*.pdf"
+  seek list --type pdf --sort size --long
+  seek list --sort modified --reverse
+  seek list --output json`,
 		Args: cobra.ExactArgs(0),
-		Run: func(cmd *cobra.Command, args []string) {
-			handlers.List(listLimit)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listOpts.Sort = services.DocumentSort(listSort)
+			return handlers.List(listOpts, listLong, output)
 		},
 	}
-	listCmd.Flags().IntVar(&listLimit, "limit", 100, "Maximum number of documents to scan")
+	listCmd.Flags().IntVar(&listOpts.Limit, "limit", services.DefaultListLimit, "Maximum number of documents to list (0 for all)")
+	listCmd.Flags().IntVar(&listOpts.Offset, "offset", 0, "Number of documents to skip")
+	listCmd.Flags().StringVar(&listOpts.Prefix, "prefix", "", "Only list documents whose filename starts with this prefix")
+	listCmd.Flags().StringVar(&listOpts.Pattern, "pattern", "", "Only list documents matching this glob (e.g. \"*.pdf\" or \"docs/*.md\")")
+	listCmd.Flags().StringVar(&listOpts.Type, "type", "", "Only list documents of this file type (e.g. pdf or md)")
+	listCmd.Flags().StringVar(&listSort, "sort", string(services.SortByName), "Sort by name, size, chunks, modified or indexed; all but name list the largest or newest first")
+	listCmd.Flags().BoolVar(&listOpts.Reverse, "reverse", false, "Reverse the sort order")
+	listCmd.Flags().BoolVar(&listLong, "long", false, "Show a table of chunk count, size, type, modification and index time")
 	rootCmd.AddCommand(listCmd)
 
+	var kbCmd = &cobra.Command{
+		Use:   "kb",
+		Short: "Manage knowledge bases",
+		Long:  "Create, list, inspect, upgrade and delete knowledge bases. Each knowledge base is a separate Qdrant collection, selected with --kb.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	kbCmd.AddCommand(&cobra.Command{
+		Use:     "create <name>",
+		Short:   "Create an empty knowledge base",
+		Example: `  seek kb create work_docs`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.CreateKB(args[0])
+		},
+	})
+
+	kbCmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Short:   "List knowledge bases",
+		Long:    "List every knowledge base on the Qdrant server with its point count and last index time. The one in use is marked with '*'.",
+		Example: `  seek kb list`,
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.ListKBs(output)
+		},
+	})
+
+	var deleteConfirmed bool
+	var kbDeleteCmd = &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "Delete a knowledge base and everything embedded in it",
+		Example: `  seek kb delete work_docs --yes`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.DeleteKB(args[0], deleteConfirmed)
+		},
+	}
+	kbDeleteCmd.Flags().BoolVar(&deleteConfirmed, "yes", false, "Confirm the deletion")
+	kbCmd.AddCommand(kbDeleteCmd)
+
+	kbCmd.AddCommand(&cobra.Command{
+		Use:   "info [name]",
+		Short: "Show details of a knowledge base",
+		Example: `  seek kb info
+  seek kb info work_docs`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return handlers.KBInfo(name, output)
+		},
+	})
+
+	kbCmd.AddCommand(&cobra.Command{
+		Use:   "upgrade [name]",
+		Short: "Build the indexes a knowledge base embedded by an older seek is missing",
+		Long:  "Build the full-text index grep uses, and the document manifest list reads, for a knowledge base embedded by an older version of seek, without embedding it again. Read-only commands never build them themselves; until they exist, grep checks every chunk and list scans every chunk.",
+		Example: `  seek kb upgrade
+  seek kb upgrade work_docs`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return handlers.UpgradeKB(name)
+		},
+	})
+	rootCmd.AddCommand(kbCmd)
+
+	var configCmd = &cobra.Command{
+		Use:         "config",
+		Short:       "Show, check and create the seek.yaml config",
+		Long:        "Seek reads $XDG_CONFIG_HOME/seek/seek.yaml and the nearest seek.yaml in the current directory or its parents. Flags override environment variables, which override the selected profile, which overrides the file's top-level settings.",
+		Annotations: map[string]string{skipConfig: "true"},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Show the effective config and where each value comes from",
+		Example: `  seek config show
+  seek config show --profile work --output yaml`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.ShowConfig(configOpts, output)
+		},
+	})
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Check the config files and every profile in them",
+		Example: `  seek config validate
+  seek config validate --config ./seek.yaml`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.ValidateConfig(configOpts)
+		},
+	})
+
+	var initProject, initForce bool
+	var configInitCmd = &cobra.Command{
+		Use:   "init",
+		Short: "Write a commented seek.yaml to start from",
+		Example: `  seek config init
+  seek config init --project`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.InitConfig(initProject, initForce)
+		},
+	}
+	configInitCmd.Flags().BoolVar(&initProject, "project", false, "Write ./seek.yaml instead of the user config in $XDG_CONFIG_HOME/seek")
+	configInitCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing file")
+	configCmd.AddCommand(configInitCmd)
+	rootCmd.AddCommand(configCmd)
+
 	var versionCmd = &cobra.Command{
-		Use:   "version",
-		Short: "Print the version number",
-		Args:  cobra.ExactArgs(0),
+		Use:         "version",
+		Short:       "Print the version number",
+		Args:        cobra.ExactArgs(0),
+		Annotations: map[string]string{skipConfig: "true"},
 		Run: func(cmd *cobra.Command, args []string) {
 			fmt.Print(strings.TrimSpace(version))
 		},
 	}
 	rootCmd.AddCommand(versionCmd)
 
-	rootCmd.AddCommand(mcp.NewCommand(logfile))
+	rootCmd.AddCommand(mcp.NewCommand())
 
 	return rootCmd
 }
 
 func main() {
-	godotenv.Overload(".env.default", ".env")
-	initCmd().Execute()
+	// Variables already set in the environment win over .env
+	godotenv.Load(".env")
+	cmd, err := initCmd().ExecuteC()
+	if err == nil {
+		return
+	}
+
+	// Usage is only still enabled when the command failed before running, on bad flags or arguments
+	if !cmd.SilenceUsage {
+		os.Exit(handlers.ExitUsage)
+	}
+	os.Exit(handlers.ExitCode(err))
 }
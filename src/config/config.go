@@ -1,10 +1,7 @@
 package config
 
 import (
-	"fmt"
-	"log"
-	"os"
-	"strconv"
+	"log/slog"
 	"sync"
 )
 
@@ -19,6 +16,17 @@ type Config struct {
 	ServerName     string
 	ServerVersion  string
 	VectorSize     uint64
+	// QueryPrefix and DocumentPrefix are put in front of search queries and document chunks
+	// before they are embedded
+	QueryPrefix    string
+	DocumentPrefix string
+
+	// Profile is the profile that was applied, if any
+	Profile string
+	// Files lists the config files that were read, lowest precedence first
+	Files []string
+	// Sources maps each setting to its value and where it came from
+	Sources map[string]Source
 }
 
 var (
@@ -34,57 +42,54 @@ func Initialize(cfg *Config) {
 
 func Get() *Config {
 	if instance == nil {
-		log.Println("Error: config not initialized")
+		slog.Error("Config not initialized")
 		return nil
 	}
 	return instance
 }
 
-func getEnv(key string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-
-	log.Println("Warning: environment variable not set:", key)
-	return ""
-}
-
-func getEnvInt(key string) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
+// Defaults are used for anything not set by a config file, the environment or a flag
+func Defaults() Settings {
+	useTLS := false
+	return Settings{
+		Collection:     "seek_collection",
+		OllamaURL:      "http://localhost:11434",
+		ChatModel:      "qwen2.5",
+		EmbeddingModel: "nomic-embed-text",
+		VectorSize:     768,
+		QdrantHost:     "localhost",
+		QdrantPort:     6334,
+		QdrantTLS:      &useTLS,
 	}
-
-	log.Println("Warning: environment variable not set:", key)
-	return 0
 }
 
 func applyDefaults(cfg *Config) *Config {
+	defaults := Defaults()
+
 	if cfg.CollectionName == "" {
-		cfg.CollectionName = getEnv("COLLECTION_NAME")
+		cfg.CollectionName = defaults.Collection
 	}
 	if cfg.OllamaURL == "" {
-		ollamaHost := getEnv("OLLAMA_HOST")
-		ollamaPort := getEnvInt("OLLAMA_PORT")
-		cfg.OllamaURL = fmt.Sprintf("http://%s:%d", ollamaHost, ollamaPort)
+		cfg.OllamaURL = defaults.OllamaURL
 	}
 	if cfg.QdrantHost == "" {
-		cfg.QdrantHost = getEnv("QDRANT_HOST")
+		cfg.QdrantHost = defaults.QdrantHost
 	}
 	if cfg.QdrantPort == 0 {
-		cfg.QdrantPort = getEnvInt("QDRANT_PORT")
+		cfg.QdrantPort = defaults.QdrantPort
+	}
+	if cfg.ChatModel == "" {
+		cfg.ChatModel = defaults.ChatModel
+	}
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = defaults.EmbeddingModel
+	}
+	if cfg.VectorSize == 0 {
+		cfg.VectorSize = defaults.VectorSize
 	}
 	if cfg.ServerVersion == "" {
 		cfg.ServerVersion = "dev"
 	}
 
-	if cfg.ChatModel == "" {
-		cfg.ChatModel = getEnv("CHAT_MODEL")
-	}
-
-	cfg.VectorSize = 768
-	cfg.EmbeddingModel = "nomic-embed-text"
-
 	return cfg
 }
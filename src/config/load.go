@@ -0,0 +1,393 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const FileName = "seek.yaml"
+
+// ErrInvalid is returned when a config file cannot be parsed or a setting has a bad value
+var ErrInvalid = errors.New("invalid config")
+
+var validCollection = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Load builds the config from, lowest precedence first: defaults, the top-level settings of the
+// config files, the selected profile in those files, environment variables and flags. The result
+// is validated before it is returned.
+func Load(opts LoadOptions) (*Config, error) {
+	paths, err := configFiles(opts.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*File, 0, len(paths))
+	for _, path := range paths {
+		file, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	profile := opts.Profile
+	if profile == "" {
+		profile = os.Getenv("SEEK_PROFILE")
+	}
+	if profile == "" {
+		// The project file's default profile wins over the user's
+		for _, file := range files {
+			if file.Profile != "" {
+				profile = file.Profile
+			}
+		}
+	}
+
+	settings := Settings{}
+	sources := map[string]Source{}
+	merge(&settings, Defaults(), "default", sources)
+
+	for i, file := range files {
+		merge(&settings, file.Settings, paths[i], sources)
+	}
+
+	if profile != "" {
+		found := false
+		for i, file := range files {
+			if profileSettings, ok := file.Profiles[profile]; ok {
+				found = true
+				merge(&settings, profileSettings, fmt.Sprintf("%s (profile %s)", paths[i], profile), sources)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: profile %q is not defined in %s", ErrInvalid, profile, describeFiles(paths))
+		}
+	}
+
+	envSettings, envOrigins, err := fromEnv()
+	if err != nil {
+		return nil, err
+	}
+	for key, origin := range envOrigins {
+		merge(&settings, pick(envSettings, key), origin, sources)
+	}
+
+	merge(&settings, opts.Flags, "flag", sources)
+
+	if err := Validate(settings); err != nil {
+		return nil, err
+	}
+
+	// Prefixes not set anywhere come from the embedding model
+	prefixes := ModelPrefixes(settings.EmbeddingModel)
+	modelOrigin := "default for " + settings.EmbeddingModel
+	if settings.QueryPrefix == nil {
+		settings.QueryPrefix = &prefixes.Query
+		sources["query_prefix"] = Source{Value: prefixes.Query, Origin: modelOrigin}
+	}
+	if settings.DocumentPrefix == nil {
+		settings.DocumentPrefix = &prefixes.Document
+		sources["document_prefix"] = Source{Value: prefixes.Document, Origin: modelOrigin}
+	}
+
+	return &Config{
+		CollectionName: settings.Collection,
+		EmbeddingModel: settings.EmbeddingModel,
+		ChatModel:      settings.ChatModel,
+		OllamaURL:      settings.OllamaURL,
+		QdrantHost:     settings.QdrantHost,
+		QdrantPort:     settings.QdrantPort,
+		QdrantUseTLS:   settings.QdrantTLS != nil && *settings.QdrantTLS,
+		VectorSize:     settings.VectorSize,
+		QueryPrefix:    *settings.QueryPrefix,
+		DocumentPrefix: *settings.DocumentPrefix,
+		Profile:        profile,
+		Files:          paths,
+		Sources:        sources,
+	}, nil
+}
+
+// ReadFile parses a config file, rejecting unknown keys so typos are caught
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file File
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, path, err)
+	}
+
+	return &file, nil
+}
+
+// UserFile returns $XDG_CONFIG_HOME/seek/seek.yaml (~/.config/seek/seek.yaml by default)
+func UserFile() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to locate home directory: %w", err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configDir, "seek", FileName), nil
+}
+
+// ProjectFile returns the nearest seek.yaml in the current directory or its parents
+func ProjectFile() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// configFiles returns the config files to read, lowest precedence first: the user's file, then
+// the project's. An explicit file (from --config or SEEK_CONFIG) replaces both and must exist.
+func configFiles(explicit string) ([]string, error) {
+	if explicit == "" {
+		explicit = os.Getenv("SEEK_CONFIG")
+	}
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		return []string{explicit}, nil
+	}
+
+	var paths []string
+
+	userFile, err := UserFile()
+	if err == nil {
+		if _, err := os.Stat(userFile); err == nil {
+			paths = append(paths, userFile)
+		}
+	}
+
+	if projectFile, ok := ProjectFile(); ok && !sameFile(projectFile, userFile) {
+		paths = append(paths, projectFile)
+	}
+
+	return paths, nil
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+func describeFiles(paths []string) string {
+	if len(paths) == 0 {
+		return "any config file"
+	}
+	return strings.Join(paths, " or ")
+}
+
+// fromEnv reads settings from environment variables, returning the variable each one came from
+func fromEnv() (Settings, map[string]string, error) {
+	var settings Settings
+	origins := map[string]string{}
+	var problems []string
+
+	setString := func(key, variable string, target *string) {
+		if value := os.Getenv(variable); value != "" {
+			*target = value
+			origins[key] = "env " + variable
+		}
+	}
+
+	setString("collection", "COLLECTION_NAME", &settings.Collection)
+	setString("chat_model", "CHAT_MODEL", &settings.ChatModel)
+	setString("embedding_model", "EMBEDDING_MODEL", &settings.EmbeddingModel)
+	setString("qdrant_host", "QDRANT_HOST", &settings.QdrantHost)
+	setString("ollama_url", "OLLAMA_URL", &settings.OllamaURL)
+
+	// OLLAMA_HOST and OLLAMA_PORT predate OLLAMA_URL and are still honoured
+	if host, port := os.Getenv("OLLAMA_HOST"), os.Getenv("OLLAMA_PORT"); settings.OllamaURL == "" && (host != "" || port != "") {
+		if host == "" {
+			host = "localhost"
+		}
+		if port == "" {
+			port = "11434"
+		}
+		settings.OllamaURL = fmt.Sprintf("http://%s:%s", host, port)
+		origins["ollama_url"] = "env OLLAMA_HOST/OLLAMA_PORT"
+	}
+
+	if value := os.Getenv("QDRANT_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("QDRANT_PORT: %q is not a number", value))
+		} else {
+			settings.QdrantPort = port
+			origins["qdrant_port"] = "env QDRANT_PORT"
+		}
+	}
+
+	if value := os.Getenv("QDRANT_TLS"); value != "" {
+		useTLS, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("QDRANT_TLS: %q is not a boolean", value))
+		} else {
+			settings.QdrantTLS = &useTLS
+			origins["qdrant_tls"] = "env QDRANT_TLS"
+		}
+	}
+
+	if len(problems) > 0 {
+		return settings, nil, fmt.Errorf("%w:\n  %s", ErrInvalid, strings.Join(problems, "\n  "))
+	}
+
+	return settings, origins, nil
+}
+
+// Validate checks every setting, reporting all problems at once
+func Validate(settings Settings) error {
+	var problems []string
+
+	if err := ValidateCollection(settings.Collection); err != nil {
+		problems = append(problems, "collection: "+err.Error())
+	}
+
+	if ollamaURL, err := url.Parse(settings.OllamaURL); err != nil || (ollamaURL.Scheme != "http" && ollamaURL.Scheme != "https") || ollamaURL.Hostname() == "" {
+		problems = append(problems, fmt.Sprintf("ollama_url: %q must be an http(s) URL with a host, e.g. http://localhost:11434", settings.OllamaURL))
+	}
+
+	if settings.ChatModel == "" {
+		problems = append(problems, "chat_model: must not be empty")
+	}
+	if settings.EmbeddingModel == "" {
+		problems = append(problems, "embedding_model: must not be empty")
+	}
+	if settings.VectorSize == 0 {
+		problems = append(problems, "vector_size: must be positive")
+	}
+
+	if settings.QdrantHost == "" {
+		problems = append(problems, "qdrant_host: must not be empty")
+	}
+	if settings.QdrantPort < 1 || settings.QdrantPort > 65535 {
+		problems = append(problems, fmt.Sprintf("qdrant_port: %d must be between 1 and 65535", settings.QdrantPort))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w:\n  %s", ErrInvalid, strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+// ValidateCollection checks that name can be used as a knowledge base (Qdrant collection) name
+func ValidateCollection(name string) error {
+	if !validCollection.MatchString(name) {
+		return fmt.Errorf("%q must be non-empty and only use letters, digits, '_' or '-'", name)
+	}
+	return nil
+}
+
+// merge copies every setting that over sets into settings, recording origin as its source
+func merge(settings *Settings, over Settings, origin string, sources map[string]Source) {
+	target := reflect.ValueOf(settings).Elem()
+	source := reflect.ValueOf(over)
+
+	for i := range source.NumField() {
+		field := source.Field(i)
+		if field.IsZero() {
+			continue
+		}
+
+		target.Field(i).Set(field)
+
+		value := field.Interface()
+		if field.Kind() == reflect.Pointer {
+			value = field.Elem().Interface()
+		}
+		sources[settingKey(source.Type().Field(i))] = Source{Value: value, Origin: origin}
+	}
+}
+
+// pick returns only the setting named key from settings
+func pick(settings Settings, key string) Settings {
+	var picked Settings
+	source := reflect.ValueOf(settings)
+	target := reflect.ValueOf(&picked).Elem()
+
+	for i := range source.NumField() {
+		if settingKey(source.Type().Field(i)) == key {
+			target.Field(i).Set(source.Field(i))
+		}
+	}
+
+	return picked
+}
+
+func settingKey(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("yaml"), ",")[0]
+}
+
+// SettingKeys returns the names of all settings in the order they are declared
+func SettingKeys() []string {
+	settingsType := reflect.TypeOf(Settings{})
+	keys := make([]string, 0, settingsType.NumField())
+	for i := range settingsType.NumField() {
+		keys = append(keys, settingKey(settingsType.Field(i)))
+	}
+	return keys
+}
+
+// ProfileNames lists the profiles defined in the config files Load would read
+func ProfileNames(configFile string) ([]string, error) {
+	paths, err := configFiles(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, path := range paths {
+		file, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for name := range file.Profiles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
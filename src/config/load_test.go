@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolate clears the environment seek reads and runs the test from an empty directory
+func isolate(t *testing.T) (configHome, projectDir string) {
+	t.Helper()
+
+	for _, variable := range []string{
+		"COLLECTION_NAME", "CHAT_MODEL", "EMBEDDING_MODEL", "OLLAMA_URL", "OLLAMA_HOST", "OLLAMA_PORT",
+		"QDRANT_HOST", "QDRANT_PORT", "QDRANT_TLS", "SEEK_PROFILE", "SEEK_CONFIG",
+	} {
+		t.Setenv(variable, "")
+	}
+
+	configHome = t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	projectDir = t.TempDir()
+	t.Chdir(projectDir)
+
+	return configHome, projectDir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	isolate(t)
+
+	cfg, err := Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.OllamaURL != "http://localhost:11434" || cfg.QdrantPort != 6334 || cfg.CollectionName != "seek_collection" {
+		t.Errorf("Load() = %+v, want the built-in defaults", cfg)
+	}
+	if cfg.Sources["qdrant_port"].Origin != "default" {
+		t.Errorf("qdrant_port origin = %q, want default", cfg.Sources["qdrant_port"].Origin)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	configHome, projectDir := isolate(t)
+
+	writeFile(t, filepath.Join(configHome, "seek", FileName), `
+collection: user_docs
+chat_model: llama3
+qdrant_port: 7000
+profiles:
+  work:
+    collection: work_docs
+    qdrant_host: qdrant.work
+`)
+	writeFile(t, filepath.Join(projectDir, FileName), `
+chat_model: mistral
+profiles:
+  work:
+    qdrant_tls: true
+`)
+	t.Setenv("QDRANT_PORT", "7100")
+	t.Setenv("OLLAMA_HOST", "ollama.local")
+
+	cfg, err := Load(LoadOptions{
+		Profile: "work",
+		Flags:   Settings{QdrantHost: "qdrant.flag"},
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"collection from profile", cfg.CollectionName, "work_docs"},
+		{"chat model from project file", cfg.ChatModel, "mistral"},
+		{"TLS from project profile", cfg.QdrantUseTLS, true},
+		{"port from env over file", cfg.QdrantPort, 7100},
+		{"ollama URL from legacy env", cfg.OllamaURL, "http://ollama.local:11434"},
+		{"host from flag over profile", cfg.QdrantHost, "qdrant.flag"},
+		{"host origin", cfg.Sources["qdrant_host"].Origin, "flag"},
+		{"port origin", cfg.Sources["qdrant_port"].Origin, "env QDRANT_PORT"},
+		{"files read", len(cfg.Files), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfileFromFileAndEnv(t *testing.T) {
+	_, projectDir := isolate(t)
+
+	writeFile(t, filepath.Join(projectDir, FileName), `
+profile: home
+profiles:
+  home:
+    collection: home_docs
+  work:
+    collection: work_docs
+`)
+
+	cfg, err := Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile != "home" || cfg.CollectionName != "home_docs" {
+		t.Errorf("Load() profile = %q collection = %q, want the file's default profile", cfg.Profile, cfg.CollectionName)
+	}
+
+	t.Setenv("SEEK_PROFILE", "work")
+	cfg, err = Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.CollectionName != "work_docs" {
+		t.Errorf("Load() with SEEK_PROFILE collection = %q, want work_docs", cfg.CollectionName)
+	}
+}
+
+func TestLoadEmbeddingPrefixes(t *testing.T) {
+	_, projectDir := isolate(t)
+
+	cfg, err := Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.QueryPrefix != "search_query: " || cfg.DocumentPrefix != "search_document: " {
+		t.Errorf("prefixes = %q, %q, want the nomic-embed-text defaults", cfg.QueryPrefix, cfg.DocumentPrefix)
+	}
+
+	writeFile(t, filepath.Join(projectDir, FileName), `
+embedding_model: my-embedder
+query_prefix: "find: "
+document_prefix: ""
+`)
+
+	cfg, err = Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.QueryPrefix != "find: " || cfg.DocumentPrefix != "" {
+		t.Errorf("prefixes = %q, %q, want the configured ones", cfg.QueryPrefix, cfg.DocumentPrefix)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		opts    LoadOptions
+		env     map[string]string
+		wantMsg string
+	}{
+		{name: "unknown key", file: "colection: typo\n", wantMsg: "field colection not found"},
+		{name: "unknown profile", file: "collection: docs\n", opts: LoadOptions{Profile: "nope"}, wantMsg: `profile "nope" is not defined`},
+		{name: "bad URL", file: "ollama_url: localhost:11434\n", wantMsg: "ollama_url"},
+		{name: "bad port", file: "qdrant_port: 70000\n", wantMsg: "qdrant_port"},
+		{name: "bad env port", env: map[string]string{"QDRANT_PORT": "abc"}, wantMsg: "QDRANT_PORT"},
+		{name: "missing explicit file", opts: LoadOptions{ConfigFile: "/does/not/exist.yaml"}, wantMsg: "exist.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, projectDir := isolate(t)
+			if tt.file != "" {
+				writeFile(t, filepath.Join(projectDir, FileName), tt.file)
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load(tt.opts)
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Load() error = %v, want ErrInvalid mentioning %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	err := Validate(Settings{Collection: "bad name", OllamaURL: "http://localhost:11434", QdrantPort: 0})
+
+	for _, key := range []string{"collection", "chat_model", "embedding_model", "vector_size", "qdrant_host", "qdrant_port"} {
+		if err == nil || !strings.Contains(err.Error(), key+":") {
+			t.Errorf("Validate() error = %v, want a problem for %s", err, key)
+		}
+	}
+}
+
+func TestTemplateIsValid(t *testing.T) {
+	isolate(t)
+	path := filepath.Join(t.TempDir(), FileName)
+
+	if err := WriteTemplate(path, false); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if err := WriteTemplate(path, false); err == nil {
+		t.Error("WriteTemplate() over an existing file succeeded, want error")
+	}
+
+	if _, err := Load(LoadOptions{ConfigFile: path, Profile: "work"}); err != nil {
+		t.Errorf("Load() of the template error = %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import "strings"
+
+// EmbeddingPrefixes are the task instructions an embedding model expects in front of the text
+type EmbeddingPrefixes struct {
+	Query    string
+	Document string
+}
+
+const retrievalInstruction = "Represent this sentence for searching relevant passages: "
+
+// knownPrefixes maps embedding model families to the prefixes they were trained with. A model
+// matches when its name, without any namespace or tag, contains the family.
+var knownPrefixes = []struct {
+	family   string
+	prefixes EmbeddingPrefixes
+}{
+	{"nomic-embed-text", EmbeddingPrefixes{Query: "search_query: ", Document: "search_document: "}},
+	{"mxbai-embed-large", EmbeddingPrefixes{Query: retrievalInstruction}},
+	{"snowflake-arctic-embed", EmbeddingPrefixes{Query: retrievalInstruction}},
+	{"bge-small-en", EmbeddingPrefixes{Query: retrievalInstruction}},
+	{"bge-base-en", EmbeddingPrefixes{Query: retrievalInstruction}},
+	{"bge-large-en", EmbeddingPrefixes{Query: retrievalInstruction}},
+	{"e5-", EmbeddingPrefixes{Query: "query: ", Document: "passage: "}},
+}
+
+// ModelPrefixes returns the built-in prefixes for an embedding model, or none for unknown models
+func ModelPrefixes(model string) EmbeddingPrefixes {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+
+	for _, known := range knownPrefixes {
+		if strings.Contains(name, known.family) {
+			return known.prefixes
+		}
+	}
+
+	return EmbeddingPrefixes{}
+}
@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestModelPrefixes(t *testing.T) {
+	tests := []struct {
+		model string
+		want  EmbeddingPrefixes
+	}{
+		{"nomic-embed-text", EmbeddingPrefixes{Query: "search_query: ", Document: "search_document: "}},
+		{"nomic-embed-text:v1.5", EmbeddingPrefixes{Query: "search_query: ", Document: "search_document: "}},
+		{"jeffh/intfloat-multilingual-e5-large:f16", EmbeddingPrefixes{Query: "query: ", Document: "passage: "}},
+		{"bge-large-en-v1.5", EmbeddingPrefixes{Query: retrievalInstruction}},
+		{"bge-m3", EmbeddingPrefixes{}},
+		{"all-minilm", EmbeddingPrefixes{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := ModelPrefixes(tt.model); got != tt.want {
+				t.Errorf("ModelPrefixes(%q) = %+v, want %+v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
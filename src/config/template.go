@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Template is the starting seek.yaml written by 'seek config init'
+const Template = `# seek configuration
+# Precedence, highest first: flags, environment variables, the selected profile, the settings below, defaults.
+
+collection: seek_collection
+ollama_url: http://localhost:11434
+chat_model: qwen2.5
+embedding_model: nomic-embed-text
+vector_size: 768
+# Prefixes put in front of queries and document chunks before embedding. They default to what the
+# embedding model expects (e.g. "search_query: " and "search_document: " for nomic-embed-text);
+# set them for other models, or to "" to turn them off. Re-run 'seek embed' after changing them.
+# query_prefix: "query: "
+# document_prefix: "passage: "
+qdrant_host: localhost
+qdrant_port: 6334
+qdrant_tls: false
+
+# Profile to use when neither --profile nor SEEK_PROFILE is given
+# profile: work
+
+# Named profiles override the settings above
+profiles:
+  work:
+    collection: work_docs
+    qdrant_host: qdrant.internal.example.com
+    qdrant_tls: true
+`
+
+// WriteTemplate writes Template to path, refusing to replace an existing file unless force is set
+func WriteTemplate(path string, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists: use --force to overwrite it", path)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(Template), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
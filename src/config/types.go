@@ -0,0 +1,39 @@
+package config
+
+// Settings are the values that can come from a config file, the environment or flags. Zero values
+// are unset, so a later layer only overrides what it actually sets.
+type Settings struct {
+	Collection     string `yaml:"collection,omitempty" json:"collection,omitempty"`
+	OllamaURL      string `yaml:"ollama_url,omitempty" json:"ollama_url,omitempty"`
+	ChatModel      string `yaml:"chat_model,omitempty" json:"chat_model,omitempty"`
+	EmbeddingModel string `yaml:"embedding_model,omitempty" json:"embedding_model,omitempty"`
+	VectorSize     uint64 `yaml:"vector_size,omitempty" json:"vector_size,omitempty"`
+	QdrantHost     string `yaml:"qdrant_host,omitempty" json:"qdrant_host,omitempty"`
+	QdrantPort     int    `yaml:"qdrant_port,omitempty" json:"qdrant_port,omitempty"`
+	QdrantTLS      *bool  `yaml:"qdrant_tls,omitempty" json:"qdrant_tls,omitempty"`
+	// The prefixes are pointers so that "" can turn off the embedding model's built-in prefix
+	QueryPrefix    *string `yaml:"query_prefix,omitempty" json:"query_prefix,omitempty"`
+	DocumentPrefix *string `yaml:"document_prefix,omitempty" json:"document_prefix,omitempty"`
+}
+
+// File is the layout of seek.yaml: top-level settings, plus named profiles that override them
+type File struct {
+	Profile  string `yaml:"profile,omitempty"`
+	Settings `yaml:",inline"`
+	Profiles map[string]Settings `yaml:"profiles,omitempty"`
+}
+
+type LoadOptions struct {
+	// ConfigFile replaces the seek.yaml lookup when set
+	ConfigFile string
+	// Profile selects a profile, overriding SEEK_PROFILE and the file's default profile
+	Profile string
+	// Flags holds the values given on the command line
+	Flags Settings
+}
+
+// Source describes where the effective value of a setting came from
+type Source struct {
+	Value  any    `json:"value" yaml:"value"`
+	Origin string `json:"origin" yaml:"origin"`
+}
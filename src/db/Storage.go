@@ -6,11 +6,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/qdrant/go-client/qdrant"
 	"github.com/rhydianjenkins/seek/src/config"
+	"github.com/rhydianjenkins/seek/src/metrics"
+	"github.com/rhydianjenkins/seek/src/ollama"
+)
+
+const (
+	// documentPageSize is how many chunks of a document are read at a time
+	documentPageSize = 1000
+	// maxEmptyPages stops reading a document whose chunks disappear while it is read
+	maxEmptyPages = 10
+	// maxPositives is how many of a document's chunks stand for it when finding similar documents
+	maxPositives = 256
 )
 
 func Connect() (*Storage, error) {
@@ -35,11 +48,37 @@ func Connect() (*Storage, error) {
 		ollamaURL:      cfg.OllamaURL,
 		vectorSize:     cfg.VectorSize,
 		embeddingModel: cfg.EmbeddingModel,
+		queryPrefix:    cfg.QueryPrefix,
+		documentPrefix: cfg.DocumentPrefix,
 	}
 
 	return storage, nil
 }
 
+// ConnectTo connects like Connect but targets the named collection; an empty name means the
+// configured one
+func ConnectTo(collection string) (*Storage, error) {
+	storage, err := Connect()
+	if err != nil {
+		return nil, err
+	}
+	return storage.ForCollection(collection), nil
+}
+
+// ForCollection returns a Storage for another collection that shares this one's connection
+func (storage *Storage) ForCollection(collection string) *Storage {
+	if collection == "" {
+		return storage
+	}
+	other := *storage
+	other.collectionName = collection
+	return &other
+}
+
+func (storage *Storage) CollectionName() string {
+	return storage.collectionName
+}
+
 func (storage *Storage) GetEmbedding(text string) ([]float32, error) {
 	if text == "" {
 		return nil, fmt.Errorf("cannot generate embedding for empty text")
@@ -50,6 +89,14 @@ func (storage *Storage) GetEmbedding(text string) ([]float32, error) {
 		textPreview = textPreview[:50] + "..."
 	}
 
+	// Every outcome is counted and timed, so failures and timeouts show up in the latency too
+	start := time.Now()
+	status := "error"
+	defer func() {
+		metrics.EmbeddingRequests.Inc(status)
+		metrics.EmbeddingDuration.ObserveSince(start, status)
+	}()
+
 	reqBody := ollamaEmbedRequest{
 		Model:  storage.embeddingModel,
 		Prompt: text,
@@ -66,102 +113,258 @@ func (storage *Storage) GetEmbedding(text string) ([]float32, error) {
 		bytes.NewBuffer(jsonData),
 	)
 	if err != nil {
-		log.Printf("Ollama API call failed for text '%s': %v", textPreview, err)
-		return nil, fmt.Errorf("failed to call Ollama API: %w", err)
+		slog.Error("Ollama API call failed", "text", textPreview, "error", err)
+		return nil, fmt.Errorf("failed to call Ollama API: %w: %v", ollama.ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		log.Printf("Ollama returned non-200 status for text '%s': %d - %s", textPreview, resp.StatusCode, string(body))
+		slog.Error("Ollama returned non-200 status", "text", textPreview, "status", resp.StatusCode, "body", string(body))
 		return nil, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var embedResp ollamaEmbedResponse
 	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
-		log.Printf("Failed to decode Ollama response for text '%s': %v", textPreview, err)
+		slog.Error("Failed to decode Ollama response", "text", textPreview, "error", err)
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if len(embedResp.Embedding) == 0 {
-		log.Printf("Ollama returned EMPTY embedding for text '%s' (model: %s)", textPreview, storage.embeddingModel)
+		slog.Error("Ollama returned an empty embedding", "text", textPreview, "model", storage.embeddingModel)
 		return nil, fmt.Errorf("ollama returned empty embedding - is the model '%s' loaded? try: ollama pull %s", storage.embeddingModel, storage.embeddingModel)
 	}
 
 	if len(embedResp.Embedding) != int(storage.vectorSize) {
-		log.Printf("Ollama returned embedding with WRONG dimensions for text '%s': got %d, expected %d", textPreview, len(embedResp.Embedding), storage.vectorSize)
+		slog.Error("Ollama returned an embedding with the wrong dimensions", "text", textPreview, "got", len(embedResp.Embedding), "expected", storage.vectorSize)
 		return nil, fmt.Errorf("ollama returned embedding with %d dimensions, expected %d - check your embedding model configuration", len(embedResp.Embedding), storage.vectorSize)
 	}
 
+	status = "ok"
+
 	return embedResp.Embedding, nil
 }
 
-func (storage *Storage) GenerateDb(points []*qdrant.PointStruct) error {
-	exists, err := storage.client.CollectionExists(context.Background(), storage.collectionName)
+// EmbedDocument embeds a document chunk with the configured document prefix
+func (storage *Storage) EmbedDocument(text string) ([]float32, error) {
+	return storage.GetEmbedding(storage.documentPrefix + text)
+}
+
+// RecordedPrefixes returns the prefixes the collection was embedded with, so changing the config
+// cannot make queries and documents disagree; collections embedded before prefixes were recorded
+// used none.
+func (storage *Storage) RecordedPrefixes() (config.EmbeddingPrefixes, error) {
+	metadata, err := storage.collectionMetadata()
+	if err != nil {
+		return config.EmbeddingPrefixes{}, err
+	}
+
+	if model := metadata["embedding_model"].GetStringValue(); model != "" && model != storage.embeddingModel {
+		slog.Warn("Collection was embedded with a different model, re-run 'seek embed'", "collection", storage.collectionName, "embedded_with", model, "configured", storage.embeddingModel)
+	}
+
+	return config.EmbeddingPrefixes{
+		Query:    metadata["query_prefix"].GetStringValue(),
+		Document: metadata["document_prefix"].GetStringValue(),
+	}, nil
+}
+
+// embeddingMetadata records how the collection's vectors are made, for RecordedPrefixes and status
+func (storage *Storage) embeddingMetadata() map[string]any {
+	return map[string]any{
+		"embedding_model": storage.embeddingModel,
+		"query_prefix":    storage.queryPrefix,
+		"document_prefix": storage.documentPrefix,
+	}
+}
 
+func (storage *Storage) collectionMetadata() (map[string]*qdrant.Value, error) {
+	start := time.Now()
+	collectionInfo, err := storage.client.GetCollectionInfo(context.Background(), storage.collectionName)
+	metrics.QdrantDuration.ObserveSince(start, "collection_info")
 	if err != nil {
-		log.Fatal("Unable to check if collection exists")
+		return nil, qdrantError("failed to get collection info", err)
+	}
+	return collectionInfo.GetConfig().GetMetadata(), nil
+}
+
+// GenerateDb replaces the collection with points, and its manifest with documents
+func (storage *Storage) GenerateDb(points []*qdrant.PointStruct, documents []DocumentInfo) error {
+	// Drop the manifest first so it can never describe the wrong points
+	if err := storage.deleteManifest(); err != nil {
 		return err
 	}
 
+	exists, err := storage.client.CollectionExists(context.Background(), storage.collectionName)
+	if err != nil {
+		return qdrantError("failed to check collection existence", err)
+	}
+
 	if exists {
-		storage.client.DeleteCollection(context.Background(), storage.collectionName)
+		if err := storage.client.DeleteCollection(context.Background(), storage.collectionName); err != nil {
+			return qdrantError("failed to delete collection", err)
+		}
+	}
+
+	indexedAt := time.Now().UTC().Format(time.RFC3339)
+	metadata := storage.embeddingMetadata()
+	metadata["indexed_at"] = indexedAt
+	if err := storage.createCollection(metadata); err != nil {
+		return err
 	}
 
-	storage.client.CreateCollection(context.Background(), &qdrant.CreateCollection{
+	start := time.Now()
+	operationInfo, err := storage.client.Upsert(context.Background(), &qdrant.UpsertPoints{
+		CollectionName: storage.collectionName,
+		Points:         points,
+	})
+	metrics.QdrantDuration.ObserveSince(start, "upsert")
+
+	if err != nil {
+		return qdrantError("failed to upsert points", err)
+	}
+
+	slog.Debug("Qdrant upsert completed", "result", operationInfo)
+
+	for i := range documents {
+		documents[i].IndexedAt = indexedAt
+	}
+	// The points are already in place, and listing scans them while the manifest is missing
+	if err := storage.writeManifest(documents); err != nil {
+		slog.Warn("Unable to write the document manifest", "collection", storage.collectionName, "error", err)
+	}
+
+	return nil
+}
+
+func (storage *Storage) createCollection(metadata map[string]any) error {
+	err := storage.client.CreateCollection(context.Background(), &qdrant.CreateCollection{
 		CollectionName: storage.collectionName,
 		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
 			Size:     storage.vectorSize,
 			Distance: qdrant.Distance_Cosine,
 		}),
+		Metadata: qdrant.NewValueMap(metadata),
 	})
+	if err != nil {
+		return qdrantError("failed to create collection", err)
+	}
+	return storage.createTextIndex()
+}
 
-	operationInfo, err := storage.client.Upsert(context.Background(), &qdrant.UpsertPoints{
+// HasTextIndex reports whether the collection has the full-text index on chunk content, which
+// collections embedded before grep existed lack
+func (storage *Storage) HasTextIndex() (bool, error) {
+	start := time.Now()
+	collectionInfo, err := storage.client.GetCollectionInfo(context.Background(), storage.collectionName)
+	metrics.QdrantDuration.ObserveSince(start, "collection_info")
+	if err != nil {
+		return false, qdrantError("failed to get collection info", err)
+	}
+
+	_, ok := collectionInfo.GetPayloadSchema()["content"]
+	return ok, nil
+}
+
+// EnsureTextIndex adds the full-text index on chunk content to collections created without it
+func (storage *Storage) EnsureTextIndex() error {
+	indexed, err := storage.HasTextIndex()
+	if err != nil || indexed {
+		return err
+	}
+
+	slog.Info("Building the full-text index", "collection", storage.collectionName)
+	return storage.createTextIndex()
+}
+
+func (storage *Storage) createTextIndex() error {
+	_, err := storage.client.CreateFieldIndex(context.Background(), &qdrant.CreateFieldIndexCollection{
 		CollectionName: storage.collectionName,
-		Points:         points,
+		Wait:           qdrant.PtrOf(true),
+		FieldName:      "content",
+		FieldType:      qdrant.FieldType_FieldTypeText.Enum(),
+		FieldIndexParams: qdrant.NewPayloadIndexParamsText(&qdrant.TextIndexParams{
+			Tokenizer: qdrant.TokenizerType_Word,
+			Lowercase: qdrant.PtrOf(true),
+		}),
 	})
-
 	if err != nil {
-		log.Fatal("Unable to upsert")
-		return err
+		return qdrantError("failed to create full-text index", err)
 	}
+	return nil
+}
 
-	log.Println("qdrant upsert result", operationInfo)
+// CreateCollection creates the collection empty, so it can be searched before anything is embedded
+func (storage *Storage) CreateCollection() error {
+	return storage.createCollection(storage.embeddingMetadata())
+}
 
+// DeleteCollection deletes the collection and its manifest
+func (storage *Storage) DeleteCollection() error {
+	if err := storage.deleteManifest(); err != nil {
+		return err
+	}
+	if err := storage.client.DeleteCollection(context.Background(), storage.collectionName); err != nil {
+		return qdrantError("failed to delete collection", err)
+	}
 	return nil
 }
 
-func (storage *Storage) Search(searchTerm string, limit int) ([]*qdrant.ScoredPoint, error) {
-	embedding, err := storage.GetEmbedding(searchTerm)
+// ListCollections returns the names of every collection on the Qdrant server, leaving out
+// document manifests
+func (storage *Storage) ListCollections() ([]string, error) {
+	defer metrics.QdrantDuration.ObserveSince(time.Now(), "list_collections")
+	names, err := storage.client.ListCollections(context.Background())
 	if err != nil {
-		log.Printf("Failed to get embedding for search term: %v", err)
-		return nil, fmt.Errorf("failed to get embedding: %w", err)
+		return nil, qdrantError("failed to list collections", err)
+	}
+
+	collections := make([]string, 0, len(names))
+	for _, name := range names {
+		if !isManifest(name) {
+			collections = append(collections, name)
+		}
 	}
+	return collections, nil
+}
 
-	query := qdrant.NewQuery(embedding...)
+// SearchVector finds the points nearest to an embedding that has already been computed
+func (storage *Storage) SearchVector(query VectorQuery) ([]*qdrant.ScoredPoint, error) {
+	request := &qdrant.QueryPoints{
+		CollectionName: storage.collectionName,
+		Query:          qdrant.NewQuery(query.Vector...),
+		WithPayload:    qdrant.NewWithPayload(true),
+		WithVectors:    qdrant.NewWithVectors(query.WithVectors),
+		Limit:          qdrant.PtrOf(uint64(query.Limit)),
+	}
+	if query.Offset > 0 {
+		request.Offset = qdrant.PtrOf(uint64(query.Offset))
+	}
+	if query.MinScore > 0 {
+		request.ScoreThreshold = qdrant.PtrOf(query.MinScore)
+	}
 
-	searchResult, err := storage.client.Query(
-		context.Background(),
-		&qdrant.QueryPoints{
-			CollectionName: storage.collectionName,
-			Query:          query,
-			WithPayload:    qdrant.NewWithPayload(true),
-			Limit:          qdrant.PtrOf(uint64(limit)),
-		},
-	)
+	defer metrics.QdrantDuration.ObserveSince(time.Now(), "query")
+	searchResult, err := storage.client.Query(context.Background(), request)
 
 	if err != nil {
-		log.Printf("Unable to search for term: %v", err)
-		return nil, fmt.Errorf("search failed: %w", err)
+		slog.Error("Unable to search for term", "error", err)
+		return nil, qdrantError("search failed", err)
 	}
 
 	return searchResult, nil
 }
 
+// Ping checks that Qdrant is reachable
+func (storage *Storage) Ping(ctx context.Context) error {
+	_, err := storage.client.HealthCheck(ctx)
+	return err
+}
+
 func (storage *Storage) GetStatus() (*CollectionStatus, error) {
 	exists, err := storage.client.CollectionExists(context.Background(), storage.collectionName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check collection existence: %w", err)
+		return nil, qdrantError("failed to check collection existence", err)
 	}
 
 	if !exists {
@@ -171,111 +374,285 @@ func (storage *Storage) GetStatus() (*CollectionStatus, error) {
 		}, nil
 	}
 
+	start := time.Now()
 	collectionInfo, err := storage.client.GetCollectionInfo(context.Background(), storage.collectionName)
+	metrics.QdrantDuration.ObserveSince(start, "collection_info")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get collection info: %w", err)
+		return nil, qdrantError("failed to get collection info", err)
 	}
 
-	return &CollectionStatus{
+	metadata := collectionInfo.GetConfig().GetMetadata()
+	status := &CollectionStatus{
 		CollectionName: storage.collectionName,
 		Exists:         true,
 		VectorCount:    collectionInfo.GetPointsCount(),
 		VectorSize:     storage.vectorSize,
-	}, nil
+		IndexedAt:      metadata["indexed_at"].GetStringValue(),
+		EmbeddingModel: metadata["embedding_model"].GetStringValue(),
+	}
+	if prefix, ok := metadata["query_prefix"]; ok {
+		status.QueryPrefix = qdrant.PtrOf(prefix.GetStringValue())
+	}
+	if prefix, ok := metadata["document_prefix"]; ok {
+		status.DocumentPrefix = qdrant.PtrOf(prefix.GetStringValue())
+	}
+
+	return status, nil
 }
 
-func (storage *Storage) GetDocumentByFilename(filename string) ([]*qdrant.ScoredPoint, error) {
-	filter := &qdrant.Filter{
-		Must: []*qdrant.Condition{
-			{
-				ConditionOneOf: &qdrant.Condition_Field{
-					Field: &qdrant.FieldCondition{
-						Key: "filename",
-						Match: &qdrant.Match{
-							MatchValue: &qdrant.Match_Keyword{
-								Keyword: filename,
-							},
-						},
-					},
-				},
-			},
-		},
+// CountChunks counts the chunks of a document, only those between from and to when they are set
+func (storage *Storage) CountChunks(filename string, from, to *int64) (uint64, error) {
+	defer metrics.QdrantDuration.ObserveSince(time.Now(), "count")
+	count, err := storage.client.Count(context.Background(), &qdrant.CountPoints{
+		CollectionName: storage.collectionName,
+		Filter:         chunkFilter(filename, from, to),
+		Exact:          qdrant.PtrOf(true),
+	})
+	if err != nil {
+		return 0, qdrantError("failed to count chunks", err)
+	}
+	return count, nil
+}
+
+// ScanDocument visits the chunks of a document in order, only those between from and to when they
+// are set. Chunks are fetched a page at a time, so huge documents are never held in memory.
+func (storage *Storage) ScanDocument(filename string, from, to *int64, visit func(*qdrant.RetrievedPoint) error) error {
+	total, err := storage.CountChunks(filename, from, to)
+	if err != nil {
+		return err
 	}
 
-	scrollResult, err := storage.client.Scroll(
+	start := int64(0)
+	if from != nil {
+		start = *from
+	}
+
+	// Chunks whose embedding failed leave gaps in the chunk indexes, so pages are read until every
+	// counted chunk has been seen
+	var seen uint64
+	emptyPages := 0
+	for seen < total {
+		end := start + documentPageSize - 1
+		if to != nil {
+			end = min(end, *to)
+		}
+
+		points, err := storage.GetChunks(filename, start, end)
+		if err != nil {
+			return err
+		}
+
+		if len(points) == 0 {
+			emptyPages++
+			if emptyPages > maxEmptyPages {
+				return fmt.Errorf("chunks of %s changed while it was being read", filename)
+			}
+		} else {
+			emptyPages = 0
+		}
+
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].Payload["chunk_index"].GetIntegerValue() < points[j].Payload["chunk_index"].GetIntegerValue()
+		})
+		for _, point := range points {
+			if err := visit(point); err != nil {
+				return err
+			}
+		}
+
+		seen += uint64(len(points))
+		if to != nil && end >= *to {
+			break
+		}
+		start = end + 1
+	}
+
+	return nil
+}
+
+func chunkFilter(filename string, from, to *int64) *qdrant.Filter {
+	conditions := []*qdrant.Condition{qdrant.NewMatchKeyword("filename", filename)}
+	if from != nil || to != nil {
+		chunkRange := &qdrant.Range{}
+		if from != nil {
+			chunkRange.Gte = qdrant.PtrOf(float64(*from))
+		}
+		if to != nil {
+			chunkRange.Lte = qdrant.PtrOf(float64(*to))
+		}
+		conditions = append(conditions, qdrant.NewRange("chunk_index", chunkRange))
+	}
+	return &qdrant.Filter{Must: conditions}
+}
+
+// GetChunks returns the chunks of a document whose chunk_index is between from and to inclusive
+func (storage *Storage) GetChunks(filename string, from, to int64) ([]*qdrant.RetrievedPoint, error) {
+	defer metrics.QdrantDuration.ObserveSince(time.Now(), "scroll")
+	points, err := storage.client.Scroll(
 		context.Background(),
 		&qdrant.ScrollPoints{
 			CollectionName: storage.collectionName,
-			Filter:         filter,
-			WithPayload:    qdrant.NewWithPayload(true),
-			Limit:          qdrant.PtrOf(uint32(1000)),
+			Filter:         chunkFilter(filename, &from, &to),
+			WithPayload:    qdrant.NewWithPayloadInclude("chunk_index", "content"),
+			Limit:          qdrant.PtrOf(uint32(to - from + 1)),
 		},
 	)
-
 	if err != nil {
-		return nil, fmt.Errorf("failed to scroll documents: %w", err)
-	}
-
-	scoredPoints := make([]*qdrant.ScoredPoint, len(scrollResult))
-	for i, point := range scrollResult {
-		scoredPoints[i] = &qdrant.ScoredPoint{
-			Id:      point.Id,
-			Payload: point.Payload,
-			Score:   1.0,
-		}
+		return nil, qdrantError("failed to scroll chunks", err)
 	}
 
-	return scoredPoints, nil
+	return points, nil
 }
 
-func (storage *Storage) ListDocuments(limit int) ([]string, error) {
-	filenameMap := make(map[string]bool)
-	var offset *qdrant.PointId
-	pageSize := uint32(10000) // Scan in batches of 10k points
+// ChunkIDs returns the IDs of a document's chunks, or only of the given chunk when it is not nil
+func (storage *Storage) ChunkIDs(filename string, chunk *int64) ([]*qdrant.PointId, error) {
+	conditions := []*qdrant.Condition{qdrant.NewMatchKeyword("filename", filename)}
+	if chunk != nil {
+		conditions = append(conditions, qdrant.NewMatchInt("chunk_index", *chunk))
+	}
 
-	for len(filenameMap) < limit {
-		scrollResult, err := storage.client.Scroll(
+	var ids []*qdrant.PointId
+	var offset *qdrant.PointId
+	for {
+		start := time.Now()
+		points, nextOffset, err := storage.client.ScrollAndOffset(
 			context.Background(),
 			&qdrant.ScrollPoints{
 				CollectionName: storage.collectionName,
-				WithPayload:    qdrant.NewWithPayload(true),
-				Limit:          qdrant.PtrOf(pageSize),
+				Filter:         &qdrant.Filter{Must: conditions},
+				WithPayload:    qdrant.NewWithPayload(false),
+				Limit:          qdrant.PtrOf(uint32(documentPageSize)),
 				Offset:         offset,
 			},
 		)
-
+		metrics.QdrantDuration.ObserveSince(start, "scroll")
 		if err != nil {
-			return nil, fmt.Errorf("failed to scroll documents: %w", err)
+			return nil, qdrantError("failed to scroll chunks", err)
 		}
 
-		if len(scrollResult) == 0 {
-			break
+		for _, point := range points {
+			ids = append(ids, point.Id)
 		}
 
-		for _, point := range scrollResult {
-			if point.Payload != nil {
-				if filename, ok := point.Payload["filename"]; ok {
-					filenameMap[filename.GetStringValue()] = true
-					if len(filenameMap) >= limit {
-						break
-					}
-				}
-			}
+		if nextOffset == nil {
+			return ids, nil
 		}
+		offset = nextOffset
+	}
+}
 
-		if len(scrollResult) < int(pageSize) {
-			break
+// SimilarGroups finds the documents nearest to the given points, leaving out excludeFilename, with
+// up to groupSize of each document's closest chunks. Qdrant queries from the stored vectors: a
+// single point's own vector, or the average of the points' vectors, so nothing is embedded again
+// or downloaded. Long documents are represented by at most maxPositives evenly spaced chunks to
+// keep the request small.
+func (storage *Storage) SimilarGroups(ids []*qdrant.PointId, excludeFilename string, limit, groupSize int) ([]*qdrant.PointGroup, error) {
+	var query *qdrant.Query
+	if len(ids) == 1 {
+		query = qdrant.NewQueryID(ids[0])
+	} else {
+		sampled := samplePoints(ids, maxPositives)
+		positive := make([]*qdrant.VectorInput, len(sampled))
+		for i, id := range sampled {
+			positive[i] = qdrant.NewVectorInputID(id)
 		}
+		query = qdrant.NewQueryRecommend(&qdrant.RecommendInput{
+			Positive: positive,
+			Strategy: qdrant.RecommendStrategy_AverageVector.Enum(),
+		})
+	}
 
-		if len(scrollResult) > 0 {
-			offset = scrollResult[len(scrollResult)-1].Id
-		}
+	return storage.queryGroups(&qdrant.QueryPointGroups{
+		Query: query,
+		Filter: &qdrant.Filter{
+			MustNot: []*qdrant.Condition{qdrant.NewMatchKeyword("filename", excludeFilename)},
+		},
+		Limit:     qdrant.PtrOf(uint64(limit)),
+		GroupSize: qdrant.PtrOf(uint64(groupSize)),
+	})
+}
+
+// samplePoints picks up to n of ids, evenly spaced
+func samplePoints(ids []*qdrant.PointId, n int) []*qdrant.PointId {
+	if len(ids) <= n {
+		return ids
+	}
+	sampled := make([]*qdrant.PointId, n)
+	for i := range sampled {
+		sampled[i] = ids[i*len(ids)/n]
 	}
+	return sampled
+}
 
-	filenames := make([]string, 0, len(filenameMap))
-	for filename := range filenameMap {
-		filenames = append(filenames, filename)
+// SearchGroups finds the documents with the chunks nearest to an embedding, with up to groupSize
+// of each document's closest chunks
+func (storage *Storage) SearchGroups(query VectorQuery, groupSize int) ([]*qdrant.PointGroup, error) {
+	request := &qdrant.QueryPointGroups{
+		Query:     qdrant.NewQuery(query.Vector...),
+		Limit:     qdrant.PtrOf(uint64(query.Limit)),
+		GroupSize: qdrant.PtrOf(uint64(groupSize)),
 	}
+	if query.MinScore > 0 {
+		request.ScoreThreshold = qdrant.PtrOf(query.MinScore)
+	}
+
+	return storage.queryGroups(request)
+}
 
-	return filenames, nil
+// queryGroups runs a query whose results are grouped by document
+func (storage *Storage) queryGroups(request *qdrant.QueryPointGroups) ([]*qdrant.PointGroup, error) {
+	request.CollectionName = storage.collectionName
+	request.GroupBy = "filename"
+	request.WithPayload = qdrant.NewWithPayload(true)
+
+	defer metrics.QdrantDuration.ObserveSince(time.Now(), "query_groups")
+	groups, err := storage.client.QueryGroups(context.Background(), request)
+	if err != nil {
+		return nil, qdrantError("grouped query failed", err)
+	}
+
+	return groups, nil
+}
+
+// ScanText visits every chunk whose content contains all the words of at least one of texts,
+// using the full-text index, or every chunk when texts is empty. Scanning stops early when visit
+// returns false.
+func (storage *Storage) ScanText(texts []string, visit func(*qdrant.RetrievedPoint) bool) error {
+	var filter *qdrant.Filter
+	if len(texts) > 0 {
+		filter = &qdrant.Filter{}
+		for _, text := range texts {
+			filter.Should = append(filter.Should, qdrant.NewMatchText("content", text))
+		}
+	}
+
+	var offset *qdrant.PointId
+	for {
+		start := time.Now()
+		points, nextOffset, err := storage.client.ScrollAndOffset(
+			context.Background(),
+			&qdrant.ScrollPoints{
+				CollectionName: storage.collectionName,
+				Filter:         filter,
+				WithPayload:    qdrant.NewWithPayloadInclude("filename", "chunk_index", "content"),
+				Limit:          qdrant.PtrOf(uint32(1000)),
+				Offset:         offset,
+			},
+		)
+		metrics.QdrantDuration.ObserveSince(start, "scroll")
+		if err != nil {
+			return qdrantError("failed to scroll chunks", err)
+		}
+
+		for _, point := range points {
+			if !visit(point) {
+				return nil
+			}
+		}
+
+		if nextOffset == nil {
+			return nil
+		}
+		offset = nextOffset
+	}
 }
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is returned when the collection or a document does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrUnavailable is returned when Qdrant cannot be reached
+	ErrUnavailable = errors.New("qdrant unavailable")
+)
+
+// qdrantError wraps an error from a Qdrant call so callers can tell a missing collection or an
+// unreachable server apart from other failures
+func qdrantError(action string, err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%s: %w: the collection does not exist, run 'seek embed' first", action, ErrNotFound)
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("%s: %w: %v", action, ErrUnavailable, err)
+	default:
+		return fmt.Errorf("%s: %w", action, err)
+	}
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/rhydianjenkins/seek/src/metrics"
+)
+
+const (
+	// manifestSuffix names the collection holding a knowledge base's document manifest; knowledge
+	// base names cannot contain '.', so it never clashes with one
+	manifestSuffix = ".manifest"
+	// manifestPageSize is how many manifest entries are written or read at a time
+	manifestPageSize = 1000
+)
+
+// isManifest reports whether a collection is a document manifest rather than a knowledge base
+func isManifest(collection string) bool {
+	return strings.HasSuffix(collection, manifestSuffix)
+}
+
+func (storage *Storage) manifestName() string {
+	return storage.collectionName + manifestSuffix
+}
+
+// ListDocuments returns one entry per document from the manifest. Collections embedded before
+// there was a manifest are scanned instead; listing only reads, so it leaves building the manifest
+// to EnsureManifest.
+func (storage *Storage) ListDocuments() ([]DocumentInfo, error) {
+	exists, err := storage.hasManifest()
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return storage.readManifest()
+	}
+
+	slog.Debug("No document manifest, scanning every chunk; run 'seek kb upgrade' to build it", "collection", storage.collectionName)
+	return storage.scanDocuments()
+}
+
+// EnsureManifest builds the manifest of collections embedded before there was one
+func (storage *Storage) EnsureManifest() error {
+	exists, err := storage.hasManifest()
+	if err != nil || exists {
+		return err
+	}
+
+	documents, err := storage.scanDocuments()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Building the document manifest", "collection", storage.collectionName, "documents", len(documents))
+	return storage.writeManifest(documents)
+}
+
+func (storage *Storage) hasManifest() (bool, error) {
+	exists, err := storage.client.CollectionExists(context.Background(), storage.manifestName())
+	if err != nil {
+		return false, qdrantError("failed to check manifest existence", err)
+	}
+	return exists, nil
+}
+
+func (storage *Storage) readManifest() ([]DocumentInfo, error) {
+	var documents []DocumentInfo
+	var offset *qdrant.PointId
+
+	for {
+		start := time.Now()
+		points, nextOffset, err := storage.client.ScrollAndOffset(
+			context.Background(),
+			&qdrant.ScrollPoints{
+				CollectionName: storage.manifestName(),
+				WithPayload:    qdrant.NewWithPayload(true),
+				Limit:          qdrant.PtrOf(uint32(manifestPageSize)),
+				Offset:         offset,
+			},
+		)
+		metrics.QdrantDuration.ObserveSince(start, "scroll")
+		if err != nil {
+			return nil, qdrantError("failed to scroll the document manifest", err)
+		}
+
+		for _, point := range points {
+			payload := point.GetPayload()
+			documents = append(documents, DocumentInfo{
+				Filename:   payload["filename"].GetStringValue(),
+				ChunkCount: int(payload["chunk_count"].GetIntegerValue()),
+				Size:       payload["size"].GetIntegerValue(),
+				FileType:   payload["file_type"].GetStringValue(),
+				Source:     payload["source"].GetStringValue(),
+				ModifiedAt: payload["mtime"].GetStringValue(),
+				IndexedAt:  payload["indexed_at"].GetStringValue(),
+			})
+		}
+
+		if nextOffset == nil {
+			return documents, nil
+		}
+		offset = nextOffset
+	}
+}
+
+// writeManifest replaces the manifest with one entry per document. A manifest that cannot be
+// written in full is removed, so listing falls back to scanning rather than missing documents.
+func (storage *Storage) writeManifest(documents []DocumentInfo) error {
+	if err := storage.deleteManifest(); err != nil {
+		return err
+	}
+
+	// Manifest entries are only payload, so the collection has no vectors
+	err := storage.client.CreateCollection(context.Background(), &qdrant.CreateCollection{
+		CollectionName: storage.manifestName(),
+		VectorsConfig:  qdrant.NewVectorsConfigMap(map[string]*qdrant.VectorParams{}),
+	})
+	if err != nil {
+		return qdrantError("failed to create the document manifest", err)
+	}
+
+	for start := 0; start < len(documents); start += manifestPageSize {
+		page := documents[start:min(start+manifestPageSize, len(documents))]
+		points := make([]*qdrant.PointStruct, len(page))
+		for i, document := range page {
+			points[i] = &qdrant.PointStruct{
+				Id:      qdrant.NewIDNum(uint64(start + i + 1)),
+				Vectors: qdrant.NewVectorsMap(map[string]*qdrant.Vector{}),
+				Payload: qdrant.NewValueMap(map[string]any{
+					"filename":    document.Filename,
+					"chunk_count": document.ChunkCount,
+					"size":        document.Size,
+					"file_type":   document.FileType,
+					"source":      document.Source,
+					"mtime":       document.ModifiedAt,
+					"indexed_at":  document.IndexedAt,
+				}),
+			}
+		}
+
+		upsertStart := time.Now()
+		_, err := storage.client.Upsert(context.Background(), &qdrant.UpsertPoints{
+			CollectionName: storage.manifestName(),
+			Wait:           qdrant.PtrOf(true),
+			Points:         points,
+		})
+		metrics.QdrantDuration.ObserveSince(upsertStart, "upsert")
+		if err != nil {
+			if deleteErr := storage.deleteManifest(); deleteErr != nil {
+				slog.Warn("Unable to remove the incomplete document manifest", "collection", storage.collectionName, "error", deleteErr)
+			}
+			return qdrantError("failed to write the document manifest", err)
+		}
+	}
+
+	return nil
+}
+
+func (storage *Storage) deleteManifest() error {
+	exists, err := storage.hasManifest()
+	if err != nil || !exists {
+		return err
+	}
+
+	if err := storage.client.DeleteCollection(context.Background(), storage.manifestName()); err != nil {
+		return qdrantError("failed to delete the document manifest", err)
+	}
+	return nil
+}
+
+// scanDocuments scans every point in the collection and aggregates one entry per document
+func (storage *Storage) scanDocuments() ([]DocumentInfo, error) {
+	metadata, err := storage.collectionMetadata()
+	if err != nil {
+		return nil, err
+	}
+	indexedAt := metadata["indexed_at"].GetStringValue()
+
+	documents := make(map[string]*DocumentInfo)
+	var offset *qdrant.PointId
+	pageSize := uint32(10000) // Scan in batches of 10k points
+
+	for {
+		start := time.Now()
+		scrollResult, nextOffset, err := storage.client.ScrollAndOffset(
+			context.Background(),
+			&qdrant.ScrollPoints{
+				CollectionName: storage.collectionName,
+				WithPayload:    qdrant.NewWithPayloadInclude("filename", "size", "file_type", "mtime"),
+				Limit:          qdrant.PtrOf(pageSize),
+				Offset:         offset,
+			},
+		)
+
+		metrics.QdrantDuration.ObserveSince(start, "scroll")
+		if err != nil {
+			return nil, qdrantError("failed to scroll documents", err)
+		}
+
+		for _, point := range scrollResult {
+			if point.Payload == nil {
+				continue
+			}
+
+			filename, ok := point.Payload["filename"]
+			if !ok {
+				continue
+			}
+
+			document, ok := documents[filename.GetStringValue()]
+			if !ok {
+				document = &DocumentInfo{
+					Filename:   filename.GetStringValue(),
+					Size:       point.Payload["size"].GetIntegerValue(),
+					FileType:   point.Payload["file_type"].GetStringValue(),
+					ModifiedAt: point.Payload["mtime"].GetStringValue(),
+					IndexedAt:  indexedAt,
+				}
+				documents[document.Filename] = document
+			}
+			document.ChunkCount++
+		}
+
+		if nextOffset == nil {
+			break
+		}
+		offset = nextOffset
+	}
+
+	infos := make([]DocumentInfo, 0, len(documents))
+	for _, document := range documents {
+		infos = append(infos, *document)
+	}
+
+	return infos, nil
+}
@@ -8,6 +8,18 @@ type Storage struct {
 	ollamaURL      string
 	vectorSize     uint64
 	embeddingModel string
+	queryPrefix    string
+	documentPrefix string
+}
+
+// VectorQuery is a nearest-neighbour search for an embedding that has already been computed
+type VectorQuery struct {
+	Vector []float32
+	Limit  int
+	Offset int
+	// MinScore drops points scoring below it; 0 keeps everything
+	MinScore    float32
+	WithVectors bool
 }
 
 type ollamaEmbedRequest struct {
@@ -24,4 +36,20 @@ type CollectionStatus struct {
 	Exists         bool   `json:"exists"`
 	VectorCount    uint64 `json:"vector_count,omitempty"`
 	VectorSize     uint64 `json:"vector_size,omitempty"`
+	IndexedAt      string `json:"indexed_at,omitempty"`
+	// EmbeddingModel and the prefixes are those the collection was embedded with
+	EmbeddingModel string  `json:"embedding_model,omitempty"`
+	QueryPrefix    *string `json:"query_prefix,omitempty"`
+	DocumentPrefix *string `json:"document_prefix,omitempty"`
+}
+
+type DocumentInfo struct {
+	Filename   string `json:"filename"`
+	ChunkCount int    `json:"chunk_count"`
+	Size       int64  `json:"size,omitempty"`
+	FileType   string `json:"file_type,omitempty"`
+	// Source is the path the document was read from when it was embedded
+	Source     string `json:"source,omitempty"`
+	ModifiedAt string `json:"modified_at,omitempty"`
+	IndexedAt  string `json:"indexed_at,omitempty"`
 }
@@ -1,86 +1,65 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"strings"
 
-	"github.com/rhydianjenkins/seek/src/config"
 	"github.com/rhydianjenkins/seek/src/ollama"
-	"github.com/rhydianjenkins/seek/src/tools"
+	"github.com/rhydianjenkins/seek/src/rag"
+	"github.com/rhydianjenkins/seek/src/services"
+	"github.com/rhydianjenkins/seek/src/sessions"
 )
 
-func AskQuestion(question string) error {
-	cfg := config.Get()
-	if cfg == nil {
-		return fmt.Errorf("config not initialized")
-	}
-
-	client := ollama.NewClient(cfg.OllamaURL, cfg.ChatModel)
-
-	messages := []ollama.Message{
-		{
-			Role: "system",
-			Content: "You are a helpful assistant with access to a knowledge base. " +
-				"When answering questions, you can use the search tool to find relevant information.",
-		},
-		{
-			Role:    "user",
-			Content: question,
-		},
-	}
+func AskQuestion(question string, format OutputFormat, sessionName string) error {
+	var store *sessions.Store
+	var session *sessions.Session
+	var history []ollama.Message
+	var citations []services.Citation
 
-	availableTools := tools.GetTools()
-
-	maxIterations := 10
-
-	for range maxIterations {
-		response, err := client.Chat(messages, availableTools, func(chunk string) {
-			fmt.Print(chunk)
-		})
+	if sessionName != "" {
+		var err error
+		store, err = sessions.Open()
 		if err != nil {
-			return fmt.Errorf("Chat request failed: %w", err)
+			return err
 		}
 
-		messages = append(messages, *response)
-
-		if len(response.ToolCalls) > 0 {
-			for _, toolCall := range response.ToolCalls {
-				// Format arguments as key-value pairs
-				var args map[string]interface{}
-				json.Unmarshal(toolCall.Function.Arguments, &args)
+		session, err = store.LoadOrCreate(sessionName)
+		if err != nil {
+			return err
+		}
 
-				var argPairs []string
-				for key, value := range args {
-					argPairs = append(argPairs, fmt.Sprintf("%s: %q", key, value))
-				}
-				argsStr := ""
-				if len(argPairs) > 0 {
-					argsStr = " (" + strings.Join(argPairs, ", ") + ")"
-				}
+		history = session.Messages
+		citations = session.Sources
+	}
 
-				fmt.Printf("\n[%s%s]\n\n", toolCall.Function.Name, argsStr)
+	conv, err := rag.NewConversation(history, citations)
+	if err != nil {
+		return err
+	}
 
-				result, err := tools.ExecuteTool(toolCall)
-				if err != nil {
-					return fmt.Errorf("Tool execution failed: %w", err)
-				}
+	// Stream the answer as it arrives unless we are producing structured output
+	if format == OutputText {
+		streamTo(conv)
+	}
 
-				messages = append(messages, ollama.Message{
-					Role:    "tool",
-					Content: result,
-				})
-			}
+	result, err := conv.Ask(context.Background(), question)
+	if err != nil {
+		return err
+	}
 
-			// Continue to next iteration to get LLM's response with tool results
-			continue
+	if session != nil {
+		session.Messages = conv.Messages()
+		session.Sources = conv.Sources()
+		if err := store.Save(session); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
 		}
+	}
 
-		// No tool calls means we got the final answer, so finish
-		// TODO Rhydian allow the user to respond if they like?
-		break
+	if format != OutputText {
+		return printStructured(format, result, nil, nil)
 	}
 
 	fmt.Println()
+	printSources(result.Citations, result.InvalidCitations)
 	return nil
 }
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rhydianjenkins/seek/src/rag"
+	"github.com/rhydianjenkins/seek/src/sessions"
+)
+
+func Chat(sessionName string) error {
+	if sessionName == "" {
+		sessionName = "chat-" + time.Now().Format("20060102-150405")
+	}
+
+	store, err := sessions.Open()
+	if err != nil {
+		return err
+	}
+
+	session, err := store.LoadOrCreate(sessionName)
+	if err != nil {
+		return err
+	}
+
+	conv, err := rag.NewConversation(session.Messages, session.Sources)
+	if err != nil {
+		return err
+	}
+	streamTo(conv)
+
+	if len(session.Messages) > 0 {
+		fmt.Printf("Resuming session %q (%d messages). Type /exit to quit.\n", sessionName, len(session.Messages))
+	} else {
+		fmt.Printf("Started session %q. Type /exit to quit.\n", sessionName)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for {
+		fmt.Print("\n> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			break
+		}
+
+		question := strings.TrimSpace(scanner.Text())
+		if question == "" {
+			continue
+		}
+		if question == "/exit" || question == "/quit" {
+			break
+		}
+
+		fmt.Println()
+		result, err := conv.Ask(context.Background(), question)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println()
+		printSources(result.Citations, result.InvalidCitations)
+
+		// Save after every answer so an interrupted chat can still be resumed
+		session.Messages = conv.Messages()
+		session.Sources = conv.Sources()
+		if err := store.Save(session); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rhydianjenkins/seek/src/config"
+)
+
+type configView struct {
+	Profile  string                   `json:"profile,omitempty"`
+	Files    []string                 `json:"files"`
+	Settings map[string]config.Source `json:"settings"`
+}
+
+func ShowConfig(opts config.LoadOptions, format OutputFormat) error {
+	cfg, err := config.Load(opts)
+	if err != nil {
+		return err
+	}
+
+	if format != OutputText {
+		files := cfg.Files
+		if files == nil {
+			files = []string{}
+		}
+		return printStructured(format, configView{Profile: cfg.Profile, Files: files, Settings: cfg.Sources}, nil, nil)
+	}
+
+	if cfg.Profile != "" {
+		fmt.Printf("Profile: %s\n", cfg.Profile)
+	}
+	if len(cfg.Files) > 0 {
+		fmt.Printf("Files: %s\n", strings.Join(cfg.Files, ", "))
+	} else {
+		fmt.Println("Files: none")
+	}
+	fmt.Println()
+
+	for _, key := range config.SettingKeys() {
+		source := cfg.Sources[key]
+		fmt.Printf("%-16s %-32v (%s)\n", key+":", displayValue(source.Value), source.Origin)
+	}
+
+	return nil
+}
+
+// displayValue quotes strings whose whitespace matters, such as embedding prefixes
+func displayValue(value any) any {
+	if s, ok := value.(string); ok && (s == "" || strings.TrimSpace(s) != s) {
+		return strconv.Quote(s)
+	}
+	return value
+}
+
+// ValidateConfig checks the selected profile and every other profile in the config files, so a
+// mistake in a profile is found before it is used
+func ValidateConfig(opts config.LoadOptions) error {
+	cfg, err := config.Load(opts)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := config.ProfileNames(opts.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, profile := range profiles {
+		profileOpts := opts
+		profileOpts.Profile = profile
+		if _, err := config.Load(profileOpts); err != nil {
+			problems = append(problems, fmt.Sprintf("profile %s: %v", profile, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w in profiles:\n%s", config.ErrInvalid, strings.Join(problems, "\n"))
+	}
+
+	if len(cfg.Files) == 0 {
+		fmt.Println("No config file found; the defaults are valid")
+		return nil
+	}
+
+	fmt.Printf("Config is valid: %s", strings.Join(cfg.Files, ", "))
+	if len(profiles) > 0 {
+		fmt.Printf(" (profiles: %s)", strings.Join(profiles, ", "))
+	}
+	fmt.Println()
+	return nil
+}
+
+func InitConfig(project bool, force bool) error {
+	path := config.FileName
+	if !project {
+		var err error
+		if path, err = config.UserFile(); err != nil {
+			return err
+		}
+	}
+
+	if err := config.WriteTemplate(path, force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
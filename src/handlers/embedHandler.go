@@ -8,7 +8,12 @@ import (
 	"github.com/rhydianjenkins/seek/src/services"
 )
 
-func Embed(dataDir string, chunkSize int) error {
+func Embed(dataDir string, chunkSize int, format OutputFormat) error {
+	if format != OutputText {
+		result, err := services.EmbedFilesWithProgress(dataDir, chunkSize, nil)
+		return printStructured(format, result, nil, err)
+	}
+
 	fmt.Printf("Starting indexing (chunk size: %d chars)\n", chunkSize)
 
 	startTime := time.Now()
@@ -53,7 +58,10 @@ func Embed(dataDir string, chunkSize int) error {
 
 	result, err := services.EmbedFilesWithProgress(dataDir, chunkSize, progressCallback)
 	if err != nil {
-		fmt.Printf("\nError: %s\n", result.Error)
+		// Finish the progress bar line before the error is printed
+		if !lastUpdate.IsZero() {
+			fmt.Println()
+		}
 		return err
 	}
 
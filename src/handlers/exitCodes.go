@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/rhydianjenkins/seek/src/config"
+	"github.com/rhydianjenkins/seek/src/services"
+	"github.com/rhydianjenkins/seek/src/sessions"
+)
+
+// Exit codes returned by the seek command
+const (
+	ExitOK          = 0
+	ExitError       = 1 // Any failure not covered below
+	ExitUsage       = 2 // Bad flags, arguments, input values or config
+	ExitNotFound    = 3 // The collection, document or session does not exist
+	ExitUnavailable = 4 // Qdrant or Ollama could not be reached
+)
+
+// ExitCode maps an error returned by a command to the process exit code
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, services.ErrInvalidInput), errors.Is(err, sessions.ErrInvalidName), errors.Is(err, config.ErrInvalid):
+		return ExitUsage
+	case errors.Is(err, services.ErrNotFound), errors.Is(err, sessions.ErrNotFound):
+		return ExitNotFound
+	case services.IsUnavailable(err):
+		return ExitUnavailable
+	default:
+		return ExitError
+	}
+}
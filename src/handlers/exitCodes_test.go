@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rhydianjenkins/seek/src/config"
+	"github.com/rhydianjenkins/seek/src/db"
+	"github.com/rhydianjenkins/seek/src/ollama"
+	"github.com/rhydianjenkins/seek/src/services"
+	"github.com/rhydianjenkins/seek/src/sessions"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"invalid input", fmt.Errorf("%w: limit must be positive", services.ErrInvalidInput), ExitUsage},
+		{"invalid session name", fmt.Errorf("%w \"../x\"", sessions.ErrInvalidName), ExitUsage},
+		{"invalid config", fmt.Errorf("%w: qdrant_port: 0 must be between 1 and 65535", config.ErrInvalid), ExitUsage},
+		{"missing document", fmt.Errorf("%w: no document with filename a.md", services.ErrNotFound), ExitNotFound},
+		{"missing session", fmt.Errorf("%w: onboarding", sessions.ErrNotFound), ExitNotFound},
+		{"qdrant down", fmt.Errorf("search failed: %w", db.ErrUnavailable), ExitUnavailable},
+		{"ollama down", fmt.Errorf("failed to call Ollama API: %w", ollama.ErrUnavailable), ExitUnavailable},
+		{"other", errors.New("boom"), ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
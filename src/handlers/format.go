@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/rhydianjenkins/seek/src/services"
+	"gopkg.in/yaml.v3"
+)
+
+type OutputFormat string
+
+const (
+	OutputText  OutputFormat = "text"
+	OutputJSON  OutputFormat = "json"
+	OutputJSONL OutputFormat = "jsonl"
+	OutputYAML  OutputFormat = "yaml"
+)
+
+func ParseOutputFormat(format string) (OutputFormat, error) {
+	switch OutputFormat(strings.ToLower(format)) {
+	case OutputText, "":
+		return OutputText, nil
+	case OutputJSON:
+		return OutputJSON, nil
+	case OutputJSONL:
+		return OutputJSONL, nil
+	case OutputYAML:
+		return OutputYAML, nil
+	default:
+		return OutputText, fmt.Errorf("%w: unknown output format %q: use text, json, jsonl or yaml", services.ErrInvalidInput, format)
+	}
+}
+
+// writeStructured serialises result in a machine-readable format. With jsonl, each element of
+// records is written on its own line, so a list can be streamed into tools like jq; a nil records
+// writes result as a single line instead.
+func writeStructured(out io.Writer, format OutputFormat, result any, records any) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case OutputJSONL:
+		encoder := json.NewEncoder(out)
+		if records == nil {
+			return encoder.Encode(result)
+		}
+		list := reflect.ValueOf(records)
+		for i := range list.Len() {
+			if err := encoder.Encode(list.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputYAML:
+		// Round trip through JSON so YAML uses the same field names as the JSON output
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("%q is not a structured output format", format)
+	}
+}
+
+// printStructured writes result to stdout, returning cmdErr so failures still print their result
+// and exit with the right code
+func printStructured(format OutputFormat, result any, records any, cmdErr error) error {
+	if err := writeStructured(os.Stdout, format, result, records); err != nil {
+		return fmt.Errorf("failed to write %s output: %w", format, err)
+	}
+	return cmdErr
+}
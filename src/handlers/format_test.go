@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, input := range []string{"", "text", "JSON", "jsonl", "yaml"} {
+		if _, err := ParseOutputFormat(input); err != nil {
+			t.Errorf("ParseOutputFormat(%q) error = %v", input, err)
+		}
+	}
+
+	if _, err := ParseOutputFormat("xml"); !errors.Is(err, services.ErrInvalidInput) {
+		t.Errorf("ParseOutputFormat(\"xml\") error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestWriteStructured(t *testing.T) {
+	results := &services.SearchResults{
+		Success: true,
+		Query:   "auth",
+		Count:   2,
+		Results: []services.SearchResult{
+			{Score: 0.5, Filename: "a.md", ChunkIndex: 0, Content: "one"},
+			{Score: 0.25, Filename: "b.md", ChunkIndex: 3, Content: "two"},
+		},
+	}
+
+	tests := []struct {
+		format  OutputFormat
+		records any
+		want    string
+	}{
+		{OutputJSONL, results.Results, `{"score":0.5,"filename":"a.md","chunk_index":0,"content":"one"}
+{"score":0.25,"filename":"b.md","chunk_index":3,"content":"two"}
+`},
+		{OutputJSONL, nil, `{"success":true,"query":"auth","results":[{"score":0.5,"filename":"a.md","chunk_index":0,"content":"one"},{"score":0.25,"filename":"b.md","chunk_index":3,"content":"two"}],"count":2}
+`},
+		{OutputYAML, results.Results, `count: 2
+query: auth
+results:
+  - chunk_index: 0
+    content: one
+    filename: a.md
+    score: 0.5
+  - chunk_index: 3
+    content: two
+    filename: b.md
+    score: 0.25
+success: true
+`},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := writeStructured(&out, tt.format, results, tt.records); err != nil {
+			t.Fatalf("writeStructured(%s) error = %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("writeStructured(%s) =\n%s\nwant\n%s", tt.format, out.String(), tt.want)
+		}
+	}
+}
@@ -1,22 +1,43 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
+	"os"
 
 	"github.com/rhydianjenkins/seek/src/services"
 )
 
-func GetDocument(filename string) error {
-	result, err := services.GetDocumentByFilename(filename)
-	if err != nil {
-		log.Fatalf("Failed to get document: %v", err)
-		return err
-	}
+// GetDocument prints a document. Text and jsonl are written chunk by chunk as they are read, so
+// huge documents start printing straight away.
+func GetDocument(opts services.DocumentOptions, format OutputFormat) error {
+	switch format {
+	case OutputText:
+		chunks := 0
+		err := services.StreamDocument(opts, func(chunk services.DocumentChunk) error {
+			if chunks == 0 {
+				fmt.Printf("\nDocument: %s\n\n", opts.Filename)
+			} else {
+				fmt.Print("\n\n")
+			}
+			chunks++
+			_, err := fmt.Print(chunk.Content)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n\nTotal chunks: %d\n", chunks)
+		return nil
 
-	fmt.Printf("\nDocument: %s\n", result.Filename)
-	fmt.Printf("Total chunks: %d\n\n", result.ChunkCount)
-	fmt.Println(result.FullText)
+	case OutputJSONL:
+		encoder := json.NewEncoder(os.Stdout)
+		return services.StreamDocument(opts, func(chunk services.DocumentChunk) error {
+			return encoder.Encode(chunk)
+		})
 
-	return nil
+	default:
+		result, err := services.GetDocument(opts)
+		return printStructured(format, result, result.Chunks, err)
+	}
 }
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+func Grep(opts services.GrepOptions, format OutputFormat) error {
+	results, err := services.Grep(opts)
+	if format != OutputText {
+		return printStructured(format, results, results.Matches, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, match := range results.Matches {
+		fmt.Printf("%s:%d:%d: %s\n", match.Filename, match.ChunkIndex, match.Line, match.Text)
+	}
+
+	if results.Truncated {
+		fmt.Printf("... more matches (use --limit to see more than %d)\n", results.Count)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+func CreateKB(name string) error {
+	if err := services.CreateKB(name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created knowledge base %s\n", name)
+	return nil
+}
+
+func ListKBs(format OutputFormat) error {
+	result, err := services.ListKBs()
+	if format != OutputText {
+		return printStructured(format, result, result.KnowledgeBases, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, kb := range result.KnowledgeBases {
+		marker := " "
+		if kb.Default {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\t%d points\t%s\n", marker, kb.Name, kb.Points, kb.IndexedAt)
+	}
+
+	return nil
+}
+
+func DeleteKB(name string, confirmed bool) error {
+	if !confirmed {
+		return fmt.Errorf("%w: deleting knowledge base %s removes everything embedded in it; pass --yes to confirm", services.ErrInvalidInput, name)
+	}
+
+	if err := services.DeleteKB(name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted knowledge base %s\n", name)
+	return nil
+}
+
+func UpgradeKB(name string) error {
+	if err := services.UpgradeKB(name); err != nil {
+		return err
+	}
+
+	if name == "" {
+		fmt.Println("Upgraded knowledge base")
+	} else {
+		fmt.Printf("Upgraded knowledge base %s\n", name)
+	}
+	return nil
+}
+
+func KBInfo(name string, format OutputFormat) error {
+	status, err := services.GetKB(name)
+	if err != nil {
+		return err
+	}
+
+	if format != OutputText {
+		return printStructured(format, status, nil, nil)
+	}
+
+	fmt.Printf("Knowledge base: %s\n", status.CollectionName)
+	fmt.Printf("Points: %d\n", status.VectorCount)
+	fmt.Printf("Vector size: %d\n", status.VectorSize)
+	if status.IndexedAt != "" {
+		fmt.Printf("Indexed at: %s\n", status.IndexedAt)
+	}
+
+	return nil
+}
@@ -2,35 +2,65 @@ package handlers
 
 import (
 	"fmt"
-	"log"
-	"sort"
+	"os"
+	"text/tabwriter"
 
-	"github.com/rhydianjenkins/seek/src/db"
+	"github.com/rhydianjenkins/seek/src/services"
 )
 
-func List(limit int) {
-	storage, err := db.Connect()
+func List(opts services.ListOptions, long bool, format OutputFormat) error {
+	result, err := services.ListDocuments(opts)
+	if format != OutputText {
+		return printStructured(format, result, result.Documents, err)
+	}
 	if err != nil {
-		log.Fatalf("Failed to connect to storage: %v", err)
+		return err
 	}
 
-	status, err := storage.GetStatus()
-	if err != nil {
-		log.Fatalf("Failed to get database status: %v", err)
+	if long {
+		if err := printDocumentTable(result); err != nil {
+			return err
+		}
+	} else {
+		for _, document := range result.Documents {
+			fmt.Println(document.Filename)
+		}
 	}
 
-	if !status.Exists {
-		return
+	if result.NextOffset > 0 {
+		fmt.Printf("... %d more (use --offset %d)\n", result.Total-result.NextOffset, result.NextOffset)
 	}
 
-	filenames, err := storage.ListDocuments(limit)
-	if err != nil {
-		log.Fatalf("Failed to list documents: %v", err)
+	return nil
+}
+
+func printDocumentTable(result *services.DocumentList) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "FILENAME\tCHUNKS\tSIZE\tTYPE\tMODIFIED\tINDEXED")
+	for _, document := range result.Documents {
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\t%s\n", document.Filename, document.ChunkCount, formatSize(document.Size), document.FileType, document.ModifiedAt, document.IndexedAt)
 	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d of %d documents\n", len(result.Documents), result.Total)
+	return nil
+}
 
-	sort.Strings(filenames)
+// formatSize formats a byte count with a binary unit, e.g. 1.5 KiB
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
 
-	for _, filename := range filenames {
-		fmt.Println(filename)
+	value := float64(bytes) / unit
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
 	}
+	return fmt.Sprintf("%.1f TiB", value)
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rhydianjenkins/seek/src/ollama"
+	"github.com/rhydianjenkins/seek/src/rag"
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+func printToolCall(toolCall ollama.ToolCall) {
+	// Format arguments as key-value pairs
+	var args map[string]interface{}
+	json.Unmarshal(toolCall.Function.Arguments, &args)
+
+	var argPairs []string
+	for key, value := range args {
+		argPairs = append(argPairs, fmt.Sprintf("%s: %q", key, value))
+	}
+	argsStr := ""
+	if len(argPairs) > 0 {
+		argsStr = " (" + strings.Join(argPairs, ", ") + ")"
+	}
+
+	fmt.Printf("\n[%s%s]\n\n", toolCall.Function.Name, argsStr)
+}
+
+func printSources(citations []services.Citation, invalid []int) {
+	if len(citations) > 0 {
+		fmt.Println("\nSources:")
+		for _, citation := range citations {
+			if citation.Score > 0 {
+				fmt.Printf("  [%d] %s (chunk %d, score %.4f)\n", citation.Number, citation.Filename, citation.ChunkIndex, citation.Score)
+			} else {
+				fmt.Printf("  [%d] %s (chunk %d)\n", citation.Number, citation.Filename, citation.ChunkIndex)
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		fmt.Printf("\nWarning: the answer cites unknown sources %v\n", invalid)
+	}
+}
+
+// streamTo makes a conversation print its answer and tool calls as they happen
+func streamTo(conv *rag.Conversation) {
+	conv.OnChunk = func(chunk string) {
+		fmt.Print(chunk)
+	}
+	conv.OnToolCall = printToolCall
+}
@@ -2,27 +2,64 @@ package handlers
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/rhydianjenkins/seek/src/services"
 )
 
-func Search(searchTerm string, limit int) error {
-	results, err := services.SearchFiles(searchTerm, limit)
+func Search(opts services.SearchOptions, format OutputFormat) error {
+	results, err := services.Search(opts)
+	if format != OutputText {
+		if opts.GroupBy == services.GroupByFile {
+			return printStructured(format, results, results.Documents, err)
+		}
+		return printStructured(format, results, results.Results, err)
+	}
 	if err != nil {
 		return err
 	}
 
+	if opts.GroupBy == services.GroupByFile {
+		fmt.Printf("\nDocuments matching: '%s'\n", results.Query)
+		fmt.Printf("Found %d documents:\n", results.Count)
+		printDocumentGroups(results.Documents)
+		return nil
+	}
+
 	fmt.Printf("\nSearch results for: '%s'\n", results.Query)
+	for _, expansion := range results.Expansions {
+		fmt.Printf("Also searched for: '%s'\n", expansion)
+	}
 	fmt.Printf("Found %d results:\n", results.Count)
 
 	for i, result := range results.Results {
 		fmt.Printf("\n--- Result %d (Score: %.4f) ---\n", i+1, result.Score)
+		if result.KB != "" {
+			fmt.Printf("Knowledge base: %s\n", result.KB)
+		}
 		fmt.Printf("File: %s\n", result.Filename)
-		fmt.Printf("Chunk: %d\n", result.ChunkIndex)
+		if result.Context != nil {
+			fmt.Printf("Chunks: %d-%d (matched %s)\n", result.Context.From, result.Context.To, joinInts(result.MatchedChunks))
+		} else {
+			fmt.Printf("Chunk: %d\n", result.ChunkIndex)
+		}
 		fmt.Println()
 		fmt.Println(result.Content)
 		fmt.Println()
 	}
 
+	if results.NextOffset > 0 {
+		fmt.Printf("More results are available (use --offset %d)\n", results.NextOffset)
+	}
+
 	return nil
 }
+
+func joinInts(values []int64) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.FormatInt(value, 10)
+	}
+	return strings.Join(parts, ", ")
+}
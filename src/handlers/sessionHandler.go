@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rhydianjenkins/seek/src/sessions"
+)
+
+func ListSessions(format OutputFormat) error {
+	store, err := sessions.Open()
+	if err != nil {
+		return err
+	}
+
+	summaries, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if format != OutputText {
+		return printStructured(format, summaries, summaries, nil)
+	}
+
+	for _, summary := range summaries {
+		fmt.Printf("%s\t%d messages\tupdated %s\n", summary.Name, summary.MessageCount, summary.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+
+	return nil
+}
+
+func DeleteSession(name string) error {
+	store, err := sessions.Open()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Delete(name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted session %s\n", name)
+	return nil
+}
+
+func ExportSession(name string, outputFile string) error {
+	store, err := sessions.Open()
+	if err != nil {
+		return err
+	}
+
+	session, err := store.Load(name)
+	if err != nil {
+		return err
+	}
+
+	markdown := sessions.ExportMarkdown(session)
+
+	if outputFile == "" {
+		fmt.Print(markdown)
+		return nil
+	}
+
+	if err := os.WriteFile(outputFile, []byte(markdown), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+
+	fmt.Printf("Exported session %s to %s\n", name, outputFile)
+	return nil
+}
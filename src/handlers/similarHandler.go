@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+// excerptLength is how much of each matching chunk is shown
+const excerptLength = 100
+
+func FindSimilar(opts services.SimilarOptions, format OutputFormat) error {
+	results, err := services.FindSimilar(opts)
+	if format != OutputText {
+		return printStructured(format, results, results.Documents, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	source := results.Filename
+	if results.Chunk != nil {
+		source = fmt.Sprintf("%s (chunk %d)", results.Filename, *results.Chunk)
+	}
+	fmt.Printf("\nDocuments similar to: %s\n", source)
+	fmt.Printf("Found %d documents:\n", results.Count)
+
+	printDocumentGroups(results.Documents)
+	return nil
+}
+
+func printDocumentGroups(documents []services.DocumentGroup) {
+	for i, document := range documents {
+		fmt.Printf("\n--- %d. %s (Score: %.4f, Total: %.4f) ---\n", i+1, document.Filename, document.Score, document.TotalScore)
+		for _, chunk := range document.Chunks {
+			fmt.Printf("Chunk %d (Score: %.4f): %s\n", chunk.ChunkIndex, chunk.Score, excerpt(chunk.Content))
+		}
+	}
+}
+
+// excerpt shortens content to its first line of at most excerptLength characters
+func excerpt(content string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if runes := []rune(line); len(runes) > excerptLength {
+		return string(runes[:excerptLength]) + "..."
+	}
+	return line
+}
@@ -1,28 +1,44 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/rhydianjenkins/seek/src/db"
 )
 
-func Status() {
+func Status(format OutputFormat) error {
 	storage, err := db.Connect()
 	if err != nil {
-		log.Fatalf("Failed to connect to storage: %v", err)
+		return fmt.Errorf("failed to connect to storage: %w", err)
 	}
 
 	status, err := storage.GetStatus()
 	if err != nil {
-		log.Fatalf("Failed to get status: %v", err)
+		return err
 	}
 
-	jsonOutput, err := json.MarshalIndent(status, "", "  ")
-	if err != nil {
-		log.Fatalf("Failed to marshal status: %v", err)
+	if format != OutputText {
+		return printStructured(format, status, nil, nil)
+	}
+
+	fmt.Printf("Collection: %s\n", status.CollectionName)
+	fmt.Printf("Exists: %v\n", status.Exists)
+	if status.Exists {
+		fmt.Printf("Vectors: %d\n", status.VectorCount)
+		fmt.Printf("Vector size: %d\n", status.VectorSize)
+		if status.IndexedAt != "" {
+			fmt.Printf("Indexed at: %s\n", status.IndexedAt)
+		}
+		if status.EmbeddingModel != "" {
+			fmt.Printf("Embedding model: %s\n", status.EmbeddingModel)
+		}
+		if status.QueryPrefix != nil {
+			fmt.Printf("Query prefix: %q\n", *status.QueryPrefix)
+		}
+		if status.DocumentPrefix != nil {
+			fmt.Printf("Document prefix: %q\n", *status.DocumentPrefix)
+		}
 	}
 
-	fmt.Println(string(jsonOutput))
+	return nil
 }
@@ -0,0 +1,156 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type contextKey struct{}
+
+var (
+	mu      sync.Mutex
+	current Options
+	logFile *os.File
+)
+
+// Configure installs a slog handler built from opts as the default logger, which the standard log
+// package also writes through. A log file opened by an earlier call is closed.
+func Configure(opts Options) error {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stderr
+	var file *os.File
+	if opts.File != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.File), 0o755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = file
+	}
+
+	handler, err := newHandler(out, opts.Format, level)
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	slog.SetDefault(slog.New(handler))
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = file
+	current = opts
+
+	return nil
+}
+
+// Current returns the options passed to the last successful Configure
+func Current() Options {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// Close closes the log file, if any, and sends logs back to stderr
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if logFile == nil {
+		return nil
+	}
+
+	handler, _ := newHandler(os.Stderr, current.Format, slog.LevelInfo)
+	slog.SetDefault(slog.New(handler))
+	err := logFile.Close()
+	logFile = nil
+	current.File = ""
+
+	return err
+}
+
+// DefaultFile returns $XDG_STATE_HOME/seek/seek.log (~/.local/state/seek/seek.log by default)
+func DefaultFile() (string, error) {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(stateDir, "seek", "seek.log"), nil
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q: use debug, info, warn or error", level)
+	}
+}
+
+func newHandler(out io.Writer, format string, level slog.Level) (slog.Handler, error) {
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return slog.NewTextHandler(out, handlerOpts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(out, handlerOpts), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q: use %s or %s", format, FormatText, FormatJSON)
+	}
+}
+
+// NewRequestID returns a short random ID for correlating the log lines of one request
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithLogger returns a context carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored by WithLogger, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
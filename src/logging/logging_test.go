@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"WARN", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestConfigureJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "seek.log")
+	if err := Configure(Options{Level: "warn", Format: FormatJSON, File: path}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	t.Cleanup(func() { Close() })
+
+	slog.Info("dropped below the level")
+	FromContext(WithLogger(context.Background(), slog.Default().With("request_id", "abc"))).Warn("kept")
+	log.Print("from the log package")
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("log file is not a single JSON entry: %q", data)
+	}
+	if entry["msg"] != "kept" || entry["request_id"] != "abc" || entry["level"] != "WARN" {
+		t.Errorf("log entry = %v, want the warning with its request_id", entry)
+	}
+}
+
+func TestConfigureRejectsUnknownFormat(t *testing.T) {
+	if err := Configure(Options{Format: "xml"}); err == nil {
+		t.Error("Configure() with format xml succeeded, want error")
+	}
+}
+
+func TestDefaultFile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+
+	got, err := DefaultFile()
+	if err != nil {
+		t.Fatalf("DefaultFile() error = %v", err)
+	}
+	if want := "/tmp/state/seek/seek.log"; got != want {
+		t.Errorf("DefaultFile() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+package logging
+
+// Options controls where logs go and what they look like
+type Options struct {
+	// Level is one of debug, info, warn or error
+	Level string
+	// Format is text or json
+	Format string
+	// File is the path logs are appended to; empty means stderr
+	File string
+}
@@ -3,15 +3,18 @@ package mcp
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rhydianjenkins/seek/src/config"
 	"github.com/rhydianjenkins/seek/src/db"
+	"github.com/rhydianjenkins/seek/src/logging"
+	"github.com/rhydianjenkins/seek/src/rag"
 	"github.com/rhydianjenkins/seek/src/services"
 )
 
-func NewRAGServer() (*MCPServer, error) {
+func NewRAGServer(opts ServerOptions) (*MCPServer, error) {
 	cfg := config.Get()
 	if cfg == nil {
 		return nil, fmt.Errorf("config not initialized: call config.Initialize() before creating server")
@@ -22,17 +25,33 @@ func NewRAGServer() (*MCPServer, error) {
 		return nil, err
 	}
 
-	mcpServer := mcp.NewServer(&mcp.Implementation{
-		Name:    cfg.ServerName,
-		Version: cfg.ServerVersion,
-	}, nil)
+	embedRoots, err := canonicalRoots(opts.EmbedRoots)
+	if err != nil {
+		return nil, err
+	}
 
 	ragServer := &MCPServer{
-		mcpServer: mcpServer,
-		storage:   storage,
+		storage:       storage,
+		embedRoots:    embedRoots,
+		embedDisabled: opts.DisableEmbed,
+		jobs:          newJobManager(),
 	}
 
+	ragServer.mcpServer = mcp.NewServer(&mcp.Implementation{
+		Name:    cfg.ServerName,
+		Version: cfg.ServerVersion,
+	}, &mcp.ServerOptions{
+		Logger:             slog.Default(),
+		PageSize:           resourcePageSize,
+		SubscribeHandler:   ragServer.handleSubscribe,
+		UnsubscribeHandler: ragServer.handleUnsubscribe,
+	})
+
+	ragServer.mcpServer.AddReceivingMiddleware(withRequestLogger, ragServer.trackInFlight, recordToolMetrics)
+
 	ragServer.registerTools()
+	ragServer.registerPrompts()
+	ragServer.registerResources()
 
 	return ragServer, nil
 }
@@ -42,19 +61,39 @@ func (rs *MCPServer) registerTools() {
 		rs.mcpServer,
 		&mcp.Tool{
 			Name:        "search",
-			Description: "Search the RAG knowledge base for relevant content using semantic similarity.",
+			Description: "Search the RAG knowledge base for relevant content using semantic similarity. Pass kbs to search several knowledge bases at once.",
 		},
 		rs.handleSearchTool,
 	)
 
-	mcp.AddTool(
-		rs.mcpServer,
-		&mcp.Tool{
-			Name:        "embed",
-			Description: "Generate embeddings for documents in a directory and store them in the knowledge base.",
-		},
-		rs.handleEmbedTool,
-	)
+	if !rs.embedDisabled {
+		mcp.AddTool(
+			rs.mcpServer,
+			&mcp.Tool{
+				Name:        "embed",
+				Description: "Start a background job that generates embeddings for documents in a directory and replaces the knowledge base with them. Returns a job ID to check with embed_status; with wait, progress is also reported through progress notifications. The directory must be inside the server's embed roots and, when the client shares roots, inside those too; the server refuses to embed without embed roots.",
+			},
+			rs.handleEmbedTool,
+		)
+
+		mcp.AddTool(
+			rs.mcpServer,
+			&mcp.Tool{
+				Name:        "embed_status",
+				Description: "Get the progress and result of an embed job by job ID, or list recent embed jobs when no ID is given.",
+			},
+			rs.handleEmbedStatusTool,
+		)
+
+		mcp.AddTool(
+			rs.mcpServer,
+			&mcp.Tool{
+				Name:        "embed_cancel",
+				Description: "Cancel a running embed job by job ID. The existing knowledge base is left unchanged. A job that is already replacing the index (writing in embed_status) can no longer be cancelled.",
+			},
+			rs.handleEmbedCancelTool,
+		)
+	}
 
 	mcp.AddTool(
 		rs.mcpServer,
@@ -69,10 +108,73 @@ func (rs *MCPServer) registerTools() {
 		rs.mcpServer,
 		&mcp.Tool{
 			Name:        "get_document",
-			Description: "Retrieve a full document by filename, returning all chunks in order.",
+			Description: "Retrieve a full document by filename, returning all chunks in order. Pass from and to to read a range of chunks of a large document. When the filename is not found, the closest filenames are suggested.",
 		},
 		rs.handleGetDocumentTool,
 	)
+
+	mcp.AddTool(
+		rs.mcpServer,
+		&mcp.Tool{
+			Name:        "list_documents",
+			Description: "List the documents in the knowledge base with their chunk count, size, type, modification and index time. Supports prefix, glob and file type filtering, sorting by name, size, chunks, modified or indexed, and offset/limit paging.",
+		},
+		rs.handleListDocumentsTool,
+	)
+
+	mcp.AddTool(
+		rs.mcpServer,
+		&mcp.Tool{
+			Name:        "grep",
+			Description: "Find lines of the indexed text matching a regular expression, for exact terms such as error codes or identifiers that semantic search can miss. Returns the file, chunk and line of each match, sorted; when there are more than limit matches, grep stops early and returns the first found.",
+		},
+		rs.handleGrepTool,
+	)
+
+	mcp.AddTool(
+		rs.mcpServer,
+		&mcp.Tool{
+			Name:        "find_similar",
+			Description: "Find the documents most like a given document, or one of its chunks, using the vectors already stored for it. The source document is left out, and each result lists the document's closest chunks.",
+		},
+		rs.handleFindSimilarTool,
+	)
+
+	mcp.AddTool(
+		rs.mcpServer,
+		&mcp.Tool{
+			Name:        "list_knowledge_bases",
+			Description: "List the knowledge bases on the server with their point counts, marking the default one. Pass a name as kb to the other tools to use it.",
+		},
+		rs.handleListKBsTool,
+	)
+
+	mcp.AddTool(
+		rs.mcpServer,
+		&mcp.Tool{
+			Name:        "ask",
+			Description: "Answer a question using the knowledge base. Seek searches the indexed documents with its own chat model and returns an answer that cites its sources as [1], [2], along with the cited chunks.",
+		},
+		rs.handleAskTool,
+	)
+}
+
+func (rs *MCPServer) registerPrompts() {
+	rs.mcpServer.AddPrompt(
+		&mcp.Prompt{
+			Name:        "ask",
+			Title:       "Ask the knowledge base",
+			Description: "Ask a question about the knowledge base: returns the question with the best matching excerpts, numbered so your model's answer can cite them.",
+			Arguments: []*mcp.PromptArgument{
+				{
+					Name:        "question",
+					Description: "The question to answer",
+					Required:    true,
+				},
+			},
+		},
+		rs.handleAskPrompt,
+	)
 }
 
 func (rs *MCPServer) handleSearchTool(
@@ -84,17 +186,34 @@ func (rs *MCPServer) handleSearchTool(
 		input.Limit = 3
 	}
 
-	log.Printf("Search tool called with query=%s, limit=%d", input.Query, input.Limit)
+	logger := logging.FromContext(ctx)
+	kbs := input.KBs
+	if len(kbs) == 0 && input.KB != "" {
+		kbs = []string{input.KB}
+	}
+
+	logger.Info("Search tool called", "query", input.Query, "limit", input.Limit, "offset", input.Offset, "minScore", input.MinScore, "kbs", kbs, "diversity", input.Diversity, "maxPerDoc", input.MaxPerDoc, "context", input.Context, "expand", input.Expand, "groupBy", input.GroupBy)
 
-	results, err := services.SearchFiles(input.Query, input.Limit)
+	results, err := services.Search(services.SearchOptions{
+		Query:     input.Query,
+		Limit:     input.Limit,
+		Offset:    input.Offset,
+		MinScore:  input.MinScore,
+		KBs:       kbs,
+		Diversity: input.Diversity,
+		MaxPerDoc: input.MaxPerDoc,
+		Context:   input.Context,
+		Expand:    services.ExpandMode(input.Expand),
+		GroupBy:   services.GroupBy(input.GroupBy),
+	})
 	if err != nil {
-		log.Printf("Search tool error: %v", err)
+		logger.Error("Search tool error", "error", err)
 		return &mcp.CallToolResult{
 			IsError: true,
 		}, results, err
 	}
 
-	log.Printf("Search tool completed: %d results found", results.Count)
+	logger.Info("Search tool completed", "results", results.Count)
 
 	return &mcp.CallToolResult{
 		IsError: false,
@@ -105,26 +224,122 @@ func (rs *MCPServer) handleEmbedTool(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
 	input EmbedToolInput,
-) (*mcp.CallToolResult, *services.EmbedResult, error) {
+) (*mcp.CallToolResult, *EmbedJobStatus, error) {
 	if input.ChunkSize == 0 {
 		input.ChunkSize = 1000
 	}
 
-	log.Printf("Embed tool called with dataDir=%s, chunkSize=%d", input.DataDir, input.ChunkSize)
+	logger := logging.FromContext(ctx)
+	logger.Info("Embed tool called", "kb", input.KB, "dataDir", input.DataDir, "chunkSize", input.ChunkSize, "wait", input.Wait)
 
-	results, err := services.EmbedFiles(input.DataDir, input.ChunkSize)
+	dataDir, err := rs.sandboxEmbedDir(ctx, req.Session, input.DataDir)
 	if err != nil {
-		log.Printf("Embed tool error: %v", err)
+		logger.Warn("Embed tool rejected", "error", err)
 		return &mcp.CallToolResult{
 			IsError: true,
-		}, results, err
+		}, &EmbedJobStatus{Status: JobFailed, Error: err.Error()}, err
+	}
+
+	// Progress is only reported while the request waits: the token expires when it returns, after
+	// which embed_status reports progress instead
+	var progress services.ProgressCallback
+	if input.Wait {
+		notify, stop := progressNotifier(logger, req.Session, req.Params.GetProgressToken())
+		defer stop()
+		progress = notify
+	}
+
+	job, err := rs.jobs.start(
+		logger,
+		input.KB,
+		dataDir,
+		input.ChunkSize,
+		progress,
+		rs.handleEmbedJobFinished,
+	)
+	if err != nil {
+		logger.Error("Embed tool error", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, &EmbedJobStatus{Status: JobFailed, Error: err.Error()}, err
+	}
+
+	if input.Wait {
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			// The client gave up on the request, so stop the job it was waiting for
+			rs.jobs.cancel(job.status.JobID)
+		}
 	}
 
-	log.Printf("Embed tool completed: %d files processed, %d chunks created", results.FilesIndexed, results.TotalChunks)
+	status, _ := rs.jobs.get(job.status.JobID)
+
+	return &mcp.CallToolResult{
+		IsError: status.Status == JobFailed,
+	}, &status, nil
+}
+
+func (rs *MCPServer) handleEmbedJobFinished(status EmbedJobStatus) {
+	// Only the default knowledge base is exposed as resources
+	if status.Status != JobCompleted || (status.KB != "" && status.KB != rs.storage.CollectionName()) {
+		return
+	}
+
+	slog.Info("Embed job completed", "job_id", status.JobID, "files", status.Result.FilesIndexed, "chunks", status.Result.TotalChunks)
+
+	if err := rs.refreshResources(context.Background()); err != nil {
+		slog.Error("Unable to refresh document resources", "job_id", status.JobID, "error", err)
+	}
+}
+
+func (rs *MCPServer) handleEmbedStatusTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input EmbedStatusToolInput,
+) (*mcp.CallToolResult, *EmbedJobList, error) {
+	logging.FromContext(ctx).Info("Embed status tool called", "jobId", input.JobID)
+
+	if input.JobID == "" {
+		return &mcp.CallToolResult{
+			IsError: false,
+		}, &EmbedJobList{Jobs: rs.jobs.list()}, nil
+	}
+
+	status, ok := rs.jobs.get(input.JobID)
+	if !ok {
+		err := fmt.Errorf("no embed job with ID %s", input.JobID)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, &EmbedJobList{Jobs: []EmbedJobStatus{}}, err
+	}
 
 	return &mcp.CallToolResult{
 		IsError: false,
-	}, results, nil
+	}, &EmbedJobList{Jobs: []EmbedJobStatus{status}}, nil
+}
+
+func (rs *MCPServer) handleEmbedCancelTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input EmbedCancelToolInput,
+) (*mcp.CallToolResult, *EmbedJobStatus, error) {
+	logger := logging.FromContext(ctx)
+	logger.Info("Embed cancel tool called", "jobId", input.JobID)
+
+	status, err := rs.jobs.cancel(input.JobID)
+	if err != nil {
+		logger.Error("Embed cancel tool error", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, &status, err
+	}
+
+	logger.Info("Embed cancel tool completed", "jobId", status.JobID, "status", status.Status)
+
+	return &mcp.CallToolResult{
+		IsError: false,
+	}, &status, nil
 }
 
 func (rs *MCPServer) handleStatusTool(
@@ -132,17 +347,24 @@ func (rs *MCPServer) handleStatusTool(
 	req *mcp.CallToolRequest,
 	input StatusToolInput,
 ) (*mcp.CallToolResult, *db.CollectionStatus, error) {
-	log.Println("Status tool called")
+	logger := logging.FromContext(ctx)
+	logger.Info("Status tool called", "kb", input.KB)
+
+	if err := services.ValidateKB(input.KB); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, nil, err
+	}
 
-	status, err := rs.storage.GetStatus()
+	status, err := rs.storage.ForCollection(input.KB).GetStatus()
 	if err != nil {
-		log.Printf("Status tool error: %v", err)
+		logger.Error("Status tool error", "error", err)
 		return &mcp.CallToolResult{
 			IsError: true,
 		}, status, err
 	}
 
-	log.Printf("Status tool completed: collection=%s, exists=%v, count=%d", status.CollectionName, status.Exists, status.VectorCount)
+	logger.Info("Status tool completed", "collection", status.CollectionName, "exists", status.Exists, "count", status.VectorCount)
 
 	return &mcp.CallToolResult{
 		IsError: false,
@@ -154,19 +376,228 @@ func (rs *MCPServer) handleGetDocumentTool(
 	req *mcp.CallToolRequest,
 	input GetDocumentToolInput,
 ) (*mcp.CallToolResult, *services.DocumentResult, error) {
-	log.Printf("Get document tool called with filename=%s", input.Filename)
+	logger := logging.FromContext(ctx)
+	logger.Info("Get document tool called", "kb", input.KB, "filename", input.Filename, "from", input.From, "to", input.To)
 
-	result, err := services.GetDocumentByFilename(input.Filename)
+	result, err := services.GetDocument(services.DocumentOptions{
+		KB:       input.KB,
+		Filename: input.Filename,
+		From:     input.From,
+		To:       input.To,
+	})
 	if err != nil {
-		log.Printf("Get document tool error: %v", err)
+		logger.Error("Get document tool error", "error", err)
 		return &mcp.CallToolResult{
 			IsError: true,
 		}, result, err
 	}
 
-	log.Printf("Get document tool completed: filename=%s, chunks=%d", result.Filename, result.ChunkCount)
+	logger.Info("Get document tool completed", "filename", result.Filename, "chunks", result.ChunkCount)
 
 	return &mcp.CallToolResult{
 		IsError: false,
 	}, result, nil
 }
+
+func (rs *MCPServer) handleListDocumentsTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ListDocumentsToolInput,
+) (*mcp.CallToolResult, *services.DocumentList, error) {
+	if input.Limit == 0 {
+		input.Limit = services.DefaultListLimit
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("List documents tool called", "kb", input.KB, "prefix", input.Prefix, "pattern", input.Pattern, "type", input.Type, "sort", input.Sort, "reverse", input.Reverse, "offset", input.Offset, "limit", input.Limit)
+
+	result, err := services.ListDocuments(services.ListOptions{
+		KB:      input.KB,
+		Prefix:  input.Prefix,
+		Pattern: input.Pattern,
+		Type:    input.Type,
+		Sort:    services.DocumentSort(input.Sort),
+		Reverse: input.Reverse,
+		Offset:  input.Offset,
+		Limit:   input.Limit,
+	})
+	if err != nil {
+		logger.Error("List documents tool error", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, result, err
+	}
+
+	// Source paths are on the server's filesystem, which clients have no business seeing
+	for i := range result.Documents {
+		result.Documents[i].Source = ""
+	}
+
+	logger.Info("List documents tool completed", "documents", len(result.Documents), "total", result.Total)
+
+	return &mcp.CallToolResult{
+		IsError: false,
+	}, result, nil
+}
+
+func (rs *MCPServer) handleGrepTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input GrepToolInput,
+) (*mcp.CallToolResult, *services.GrepResults, error) {
+	if input.Limit == 0 {
+		input.Limit = 100
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("Grep tool called", "kb", input.KB, "pattern", input.Pattern, "ignoreCase", input.IgnoreCase, "word", input.Word, "limit", input.Limit)
+
+	results, err := services.Grep(services.GrepOptions{
+		KB:         input.KB,
+		Pattern:    input.Pattern,
+		IgnoreCase: input.IgnoreCase,
+		Word:       input.Word,
+		Limit:      input.Limit,
+	})
+	if err != nil {
+		logger.Error("Grep tool error", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, results, err
+	}
+
+	logger.Info("Grep tool completed", "matches", results.Count, "truncated", results.Truncated)
+
+	return &mcp.CallToolResult{
+		IsError: false,
+	}, results, nil
+}
+
+func (rs *MCPServer) handleFindSimilarTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input FindSimilarToolInput,
+) (*mcp.CallToolResult, *services.SimilarResults, error) {
+	if input.Limit == 0 {
+		input.Limit = 5
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("Find similar tool called", "kb", input.KB, "filename", input.Filename, "chunk", input.Chunk, "limit", input.Limit)
+
+	results, err := services.FindSimilar(services.SimilarOptions{
+		KB:       input.KB,
+		Filename: input.Filename,
+		Chunk:    input.Chunk,
+		Limit:    input.Limit,
+	})
+	if err != nil {
+		logger.Error("Find similar tool error", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, results, err
+	}
+
+	logger.Info("Find similar tool completed", "results", results.Count)
+
+	return &mcp.CallToolResult{
+		IsError: false,
+	}, results, nil
+}
+
+func (rs *MCPServer) handleListKBsTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ListKBsToolInput,
+) (*mcp.CallToolResult, *services.KBList, error) {
+	logger := logging.FromContext(ctx)
+	logger.Info("List knowledge bases tool called")
+
+	result, err := services.ListKBs()
+	if err != nil {
+		logger.Error("List knowledge bases tool error", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, result, err
+	}
+
+	logger.Info("List knowledge bases tool completed", "count", len(result.KnowledgeBases))
+
+	return &mcp.CallToolResult{
+		IsError: false,
+	}, result, nil
+}
+
+func (rs *MCPServer) handleAskTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input AskToolInput,
+) (*mcp.CallToolResult, *services.AskResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Info("Ask tool called", "question", input.Question)
+
+	result, err := rag.Ask(ctx, input.Question)
+	if err != nil {
+		logger.Error("Ask tool error", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+		}, result, err
+	}
+
+	logger.Info("Ask tool completed", "citations", len(result.Citations))
+
+	return &mcp.CallToolResult{
+		IsError: false,
+	}, result, nil
+}
+
+// askPromptResults is how many excerpts the ask prompt includes
+const askPromptResults = 5
+
+// handleAskPrompt returns a prompt template for the client's own model: the question with the
+// chunks that best match it, numbered so the answer can cite them. No model runs on the server.
+func (rs *MCPServer) handleAskPrompt(
+	ctx context.Context,
+	req *mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	question := req.Params.Arguments["question"]
+	if question == "" {
+		return nil, fmt.Errorf("missing required argument: question")
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("Ask prompt requested", "question", question)
+
+	results, err := services.Search(services.SearchOptions{
+		Query: question,
+		Limit: askPromptResults,
+	})
+	if err != nil {
+		logger.Error("Ask prompt error", "error", err)
+		return nil, err
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Answer the question using only the excerpts from the knowledge base below. " +
+		"Cite the excerpts that support each statement by their numbers in square brackets, for example [1] or [2][3]. " +
+		"If the excerpts do not answer the question, say so.\n\n")
+	if len(results.Results) == 0 {
+		prompt.WriteString("No excerpts matched the question.\n\n")
+	}
+	for i, result := range results.Results {
+		fmt.Fprintf(&prompt, "[%d] %s (chunk %d)\n%s\n\n", i+1, result.Filename, result.ChunkIndex, result.Content)
+	}
+	fmt.Fprintf(&prompt, "Question: %s", question)
+
+	logger.Info("Ask prompt completed", "excerpts", len(results.Results))
+
+	return &mcp.GetPromptResult{
+		Description: "Question with excerpts from the knowledge base",
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: prompt.String()},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+
+	// maxRequestBodySize caps the JSON-RPC messages read before scopes are checked
+	maxRequestBodySize = 4 << 20
+)
+
+// readTools lists the tools a read token may call. Every other tool, including any added later,
+// needs the write scope until it is listed here.
+var readTools = map[string]bool{
+	"search":               true,
+	"get_document":         true,
+	"status":               true,
+	"list_documents":       true,
+	"grep":                 true,
+	"find_similar":         true,
+	"list_knowledge_bases": true,
+	"embed_status":         true,
+}
+
+func requiredScope(tool string) string {
+	if readTools[tool] {
+		return ScopeRead
+	}
+	return ScopeWrite
+}
+
+// LoadTokens reads a tokens file with one "<name> <token> <scope>[,<scope>...]" entry per line.
+// Blank lines and lines starting with '#' are ignored.
+func LoadTokens(path string) ([]APIToken, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tokens file: %w", err)
+	}
+	defer file.Close()
+
+	var tokens []APIToken
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected \"<name> <token> <scopes>\"", path, lineNumber)
+		}
+
+		scopes := strings.Split(fields[2], ",")
+		for _, scope := range scopes {
+			if scope != ScopeRead && scope != ScopeWrite {
+				return nil, fmt.Errorf("%s:%d: unknown scope %q (use %s or %s)", path, lineNumber, scope, ScopeRead, ScopeWrite)
+			}
+		}
+
+		tokens = append(tokens, APIToken{
+			Name:   fields[0],
+			Token:  fields[1],
+			Scopes: scopes,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens found in %s", path)
+	}
+
+	return tokens, nil
+}
+
+func tokenVerifier(tokens []APIToken) auth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+		for _, candidate := range tokens {
+			if subtle.ConstantTimeCompare([]byte(candidate.Token), []byte(token)) == 1 {
+				return &auth.TokenInfo{
+					Scopes:     candidate.Scopes,
+					Expiration: time.Now().Add(time.Hour),
+					UserID:     candidate.Name,
+				}, nil
+			}
+		}
+		return nil, auth.ErrInvalidToken
+	}
+}
+
+// authMiddleware accepts either "Authorization: Bearer <token>" or "X-API-Key: <token>", then
+// rejects tool calls the token is not scoped for
+func authMiddleware(tokens []APIToken) func(http.Handler) http.Handler {
+	requireToken := auth.RequireBearerToken(tokenVerifier(tokens), nil)
+
+	return func(next http.Handler) http.Handler {
+		protected := requireToken(scopeMiddleware(next))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiKey := r.Header.Get("X-API-Key"); apiKey != "" && r.Header.Get("Authorization") == "" {
+				r.Header.Set("Authorization", "Bearer "+apiKey)
+			}
+			protected.ServeHTTP(w, r)
+		})
+	}
+}
+
+type jsonRPCCall struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Name string `json:"name"`
+	} `json:"params"`
+}
+
+// scopeMiddleware inspects JSON-RPC messages before they reach the transport, so scopes are
+// enforced the same way for SSE and Streamable HTTP sessions
+func scopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		tokenInfo := auth.TokenInfoFromContext(r.Context())
+
+		for _, call := range parseCalls(body) {
+			if call.Method != "tools/call" {
+				continue
+			}
+
+			scope := requiredScope(call.Params.Name)
+			if tokenInfo != nil && slices.Contains(tokenInfo.Scopes, scope) {
+				continue
+			}
+
+			slog.Warn("Denied tool call", "tool", call.Params.Name, "token", tokenUser(tokenInfo), "missingScope", scope)
+			writeScopeError(w, call, scope)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseCalls decodes a single JSON-RPC message or a batch; anything else is left to the transport
+func parseCalls(body []byte) []jsonRPCCall {
+	trimmed := bytes.TrimSpace(body)
+
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var calls []jsonRPCCall
+		if err := json.Unmarshal(trimmed, &calls); err == nil {
+			return calls
+		}
+		return nil
+	}
+
+	var call jsonRPCCall
+	if err := json.Unmarshal(trimmed, &call); err == nil {
+		return []jsonRPCCall{call}
+	}
+	return nil
+}
+
+func writeScopeError(w http.ResponseWriter, call jsonRPCCall, scope string) {
+	response := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      call.ID,
+		"error": map[string]any{
+			"code":    -32001,
+			"message": fmt.Sprintf("token is not allowed to call %s: requires scope %q", call.Params.Name, scope),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(response)
+}
+
+func tokenUser(tokenInfo *auth.TokenInfo) string {
+	if tokenInfo == nil {
+		return "<none>"
+	}
+	return tokenInfo.UserID
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.txt")
+	content := "# name token scopes\nci ci-secret read\n\nadmin admin-secret read,write\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := LoadTokens(path)
+	if err != nil {
+		t.Fatalf("LoadTokens() error = %v", err)
+	}
+
+	if len(tokens) != 2 || tokens[0].Name != "ci" || len(tokens[1].Scopes) != 2 {
+		t.Errorf("LoadTokens() = %+v, want ci (read) and admin (read,write)", tokens)
+	}
+
+	if err := os.WriteFile(path, []byte("ci ci-secret admin\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTokens(path); err == nil {
+		t.Errorf("LoadTokens() accepted an unknown scope")
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	tokens := []APIToken{
+		{Name: "reader", Token: "read-token", Scopes: []string{ScopeRead}},
+		{Name: "admin", Token: "admin-token", Scopes: []string{ScopeRead, ScopeWrite}},
+	}
+
+	handler := authMiddleware(tokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	searchCall := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":{"query":"x"}}}`
+	embedCall := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"embed","arguments":{"dataDir":"/etc"}}}`
+	batchCall := "[" + searchCall + "," + embedCall + "]"
+
+	askCall := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"ask","arguments":{"question":"x"}}}`
+	unknownCall := `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"new_tool","arguments":{}}}`
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		body     string
+		expected int
+	}{
+		{"no token", "", "", searchCall, http.StatusUnauthorized},
+		{"wrong token", "Authorization", "Bearer nope", searchCall, http.StatusUnauthorized},
+		{"bearer read token searches", "Authorization", "Bearer read-token", searchCall, http.StatusOK},
+		{"api key read token searches", "X-API-Key", "read-token", searchCall, http.StatusOK},
+		{"read token cannot embed", "Authorization", "Bearer read-token", embedCall, http.StatusForbidden},
+		{"read token cannot embed in a batch", "X-API-Key", "read-token", batchCall, http.StatusForbidden},
+		{"admin token can embed", "Authorization", "Bearer admin-token", embedCall, http.StatusOK},
+		{"read token cannot ask", "Authorization", "Bearer read-token", askCall, http.StatusForbidden},
+		{"read token cannot call an unlisted tool", "Authorization", "Bearer read-token", unknownCall, http.StatusForbidden},
+		{"admin token can call an unlisted tool", "Authorization", "Bearer admin-token", unknownCall, http.StatusOK},
+		{"oversized body", "Authorization", "Bearer admin-token", searchCall + strings.Repeat(" ", maxRequestBodySize), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expected {
+				t.Errorf("status = %d, want %d (body: %s)", recorder.Code, tt.expected, recorder.Body.String())
+			}
+		})
+	}
+}
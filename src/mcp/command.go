@@ -2,55 +2,74 @@ package mcp
 
 import (
 	"context"
-	"log"
-	"os"
+	"fmt"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/rhydianjenkins/seek/src/logging"
+	"github.com/rhydianjenkins/seek/src/services"
 	"github.com/spf13/cobra"
 )
 
-func NewCommand(logfile string) *cobra.Command {
+func NewCommand() *cobra.Command {
 	var httpMode bool
-	var httpPort int
+	var httpOpts HTTPOptions
+	var serverOpts ServerOptions
 
 	cmd := &cobra.Command{
 		Use:   "mcp",
 		Short: "Run the MCP server over stdio or HTTP",
-		Long:  "Starts the MCP server using stdio transport (default) or HTTP transport with --http flag",
-		Args:  cobra.ExactArgs(0),
-		Run: func(cmd *cobra.Command, args []string) {
-			// Only set up log file for stdio mode
-			if !httpMode {
-				logFile, err := os.OpenFile(logfile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		Long:  "Starts the MCP server using stdio transport (default) or HTTP transport with --http flag. Over HTTP, --transport selects the legacy SSE transport or the Streamable HTTP transport.",
+		Example: `  seek mcp --embed-root ~/docs
+  seek mcp --disable-embed
+  seek mcp --http --port 8080
+  seek mcp --http --transport streamable --session-timeout 30m
+  seek mcp --http --bind 0.0.0.0 --auth-tokens tokens.txt --tls-cert cert.pem --tls-key key.pem`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !httpMode && cmd.Flags().Changed("transport") {
+				return fmt.Errorf("%w: --transport can only be used with --http", services.ErrInvalidInput)
+			}
+
+			// stdio mode cannot log to the terminal, so default to a log file
+			if opts := logging.Current(); !httpMode && opts.File == "" {
+				logFile, err := logging.DefaultFile()
 				if err != nil {
-					log.Fatalf("Failed to open log file: %v", err)
+					return err
+				}
+				opts.File = logFile
+				if err := logging.Configure(opts); err != nil {
+					return err
 				}
-				defer logFile.Close()
-				log.SetOutput(logFile)
+				defer logging.Close()
 			}
 
-			ragServer, err := NewRAGServer()
+			ragServer, err := NewRAGServer(serverOpts)
 			if err != nil {
-				log.Fatalf("Failed to create RAG server: %v", err)
+				return fmt.Errorf("failed to create RAG server: %w", err)
 			}
 
 			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
 			if httpMode {
-				if err := ragServer.RunHTTP(ctx, httpPort); err != nil {
-					log.Fatalf("MCP server error: %v", err)
-				}
-			} else {
-				if err := ragServer.Run(ctx); err != nil {
-					log.Fatalf("MCP server error: %v", err)
-				}
+				return ragServer.RunHTTP(ctx, httpOpts)
 			}
+			return ragServer.Run(ctx)
 		},
 	}
 
 	cmd.Flags().BoolVar(&httpMode, "http", false, "Run server in HTTP mode instead of stdio")
-	cmd.Flags().IntVar(&httpPort, "port", 8080, "Port to listen on when using --http mode")
+	cmd.Flags().StringArrayVar(&serverOpts.EmbedRoots, "embed-root", nil, "Directory the embed tool may index (repeatable); without one, the embed tool refuses to run")
+	cmd.Flags().BoolVar(&serverOpts.DisableEmbed, "disable-embed", false, "Do not offer the embed tool to clients")
+	cmd.Flags().StringVar(&httpOpts.Bind, "bind", "127.0.0.1", "Address to bind to when using --http mode (0.0.0.0 for all interfaces)")
+	cmd.Flags().IntVar(&httpOpts.Port, "port", 8080, "Port to listen on when using --http mode")
+	cmd.Flags().StringVar(&httpOpts.TokensFile, "auth-tokens", "", "File of \"<name> <token> <scopes>\" lines; when set, requests need a matching bearer token or X-API-Key")
+	cmd.Flags().StringVar(&httpOpts.TLSCertFile, "tls-cert", "", "TLS certificate file; serves HTTPS together with --tls-key")
+	cmd.Flags().StringVar(&httpOpts.TLSKeyFile, "tls-key", "", "TLS private key file")
+	cmd.Flags().StringVar(&httpOpts.Transport, "transport", TransportSSE, "HTTP transport to serve on /mcp: sse or streamable")
+	cmd.Flags().DurationVar(&httpOpts.SessionTimeout, "session-timeout", 30*time.Minute, "Close idle streamable HTTP sessions after this long (0 to keep them forever)")
+	cmd.Flags().DurationVar(&httpOpts.ShutdownTimeout, "shutdown-timeout", 10*time.Second, "How long to wait for in-flight requests when shutting down")
 
 	return cmd
 }
@@ -2,30 +2,97 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rhydianjenkins/seek/src/metrics"
+)
+
+const (
+	TransportSSE        = "sse"
+	TransportStreamable = "streamable"
 )
 
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		log.Printf("HTTP %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
 		next.ServeHTTP(w, r)
-		log.Printf("Completed %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+		slog.Info("HTTP request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr, "duration", time.Since(start))
 	})
 }
 
-func (rs *MCPServer) RunHTTP(ctx context.Context, port int) error {
-	addr := fmt.Sprintf(":%d", port)
-	log.Printf("Starting RAG MCP server on HTTP transport at %s", addr)
+// trackInFlight counts requests that are being handled so shutdown can wait for them to finish
+func (rs *MCPServer) trackInFlight(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if strings.HasPrefix(method, "notifications/") {
+			return next(ctx, method, req)
+		}
+
+		rs.inFlight.Add(1)
+		defer rs.inFlight.Add(-1)
+		return next(ctx, method, req)
+	}
+}
 
-	handler := mcp.NewSSEHandler(func(req *http.Request) *mcp.Server {
+func (rs *MCPServer) newTransportHandler(opts HTTPOptions) (http.Handler, error) {
+	getServer := func(req *http.Request) *mcp.Server {
 		return rs.mcpServer
-	}, &mcp.SSEOptions{})
+	}
+
+	switch opts.Transport {
+	case TransportSSE, "":
+		return mcp.NewSSEHandler(getServer, &mcp.SSEOptions{}), nil
+	case TransportStreamable:
+		return mcp.NewStreamableHTTPHandler(getServer, &mcp.StreamableHTTPOptions{
+			SessionTimeout: opts.SessionTimeout,
+			Logger:         slog.Default(),
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q: use %q or %q", opts.Transport, TransportSSE, TransportStreamable)
+	}
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (rs *MCPServer) RunHTTP(ctx context.Context, opts HTTPOptions) error {
+	handler, err := rs.newTransportHandler(opts)
+	if err != nil {
+		return err
+	}
+
+	if (opts.TLSCertFile == "") != (opts.TLSKeyFile == "") {
+		return fmt.Errorf("both a TLS certificate and key are required to enable TLS")
+	}
+
+	// Metrics name tools and knowledge base activity, so they need a token just like /mcp
+	metricsHandler := metrics.Default.Handler()
+	if opts.TokensFile != "" {
+		tokens, err := LoadTokens(opts.TokensFile)
+		if err != nil {
+			return err
+		}
+		handler = authMiddleware(tokens)(handler)
+		metricsHandler = authMiddleware(tokens)(metricsHandler)
+		slog.Info("Authentication enabled", "tokens", len(tokens))
+	} else if !isLoopback(opts.Bind) {
+		slog.Warn("Serving without authentication; any client can call every tool, including embed", "bind", opts.Bind)
+	}
+
+	addr := net.JoinHostPort(opts.Bind, strconv.Itoa(opts.Port))
+	slog.Info("Starting RAG MCP server on HTTP transport", "transport", opts.Transport, "addr", addr)
 
 	mux := http.NewServeMux()
 	mux.Handle("/mcp", handler)
@@ -33,6 +100,9 @@ func (rs *MCPServer) RunHTTP(ctx context.Context, port int) error {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	mux.HandleFunc("/ready", rs.handleReady)
+	mux.Handle("/metrics", metricsHandler)
+	metrics.Default.OnScrape(rs.updateCollectionMetrics)
 
 	loggedHandler := loggingMiddleware(mux)
 
@@ -43,17 +113,70 @@ func (rs *MCPServer) RunHTTP(ctx context.Context, port int) error {
 
 	errChan := make(chan error, 1)
 	go func() {
-		log.Printf("HTTP server listening on %s", addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if opts.TLSCertFile != "" {
+			slog.Info("HTTPS server listening", "addr", addr)
+			err = server.ListenAndServeTLS(opts.TLSCertFile, opts.TLSKeyFile)
+		} else {
+			slog.Info("HTTP server listening", "addr", addr)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- fmt.Errorf("HTTP server error: %w", err)
 		}
 	}()
 
 	select {
 	case <-ctx.Done():
-		log.Println("Shutting down HTTP server...")
-		return server.Shutdown(context.Background())
+		return rs.shutdown(server, opts.ShutdownTimeout)
 	case err := <-errChan:
 		return err
 	}
 }
+
+// shutdown stops accepting connections, lets in-flight requests finish, then closes the remaining
+// sessions so their long-lived streams end. Anything still open at the deadline is closed forcibly.
+func (rs *MCPServer) shutdown(server *http.Server, timeout time.Duration) error {
+	slog.Info("Shutting down HTTP server", "timeout", timeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(shutdownCtx)
+	}()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for rs.inFlight.Load() > 0 && shutdownCtx.Err() == nil {
+		select {
+		case <-ticker.C:
+		case <-shutdownCtx.Done():
+		}
+	}
+
+	if pending := rs.inFlight.Load(); pending > 0 {
+		slog.Warn("Timed out waiting for in-flight requests", "pending", pending)
+	} else {
+		slog.Info("All in-flight requests completed")
+	}
+
+	sessionCount := 0
+	for session := range rs.mcpServer.Sessions() {
+		session.Close()
+		sessionCount++
+	}
+	slog.Info("Closed MCP sessions", "count", sessionCount)
+
+	if err := <-shutdownErr; err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			slog.Warn("Graceful shutdown timed out, closing remaining connections")
+			return server.Close()
+		}
+		return err
+	}
+
+	return nil
+}
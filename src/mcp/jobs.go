@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+
+	// Finished jobs beyond this many are forgotten, oldest first
+	maxFinishedJobs = 20
+
+	progressInterval = 250 * time.Millisecond
+)
+
+type embedJob struct {
+	status EmbedJobStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// embedFunc runs an embed; it is services.EmbedFilesWithContext outside tests
+type embedFunc func(ctx context.Context, kb string, dataDir string, chunkSize int, progress services.ProgressCallback, beginWrite func() bool) (*services.EmbedResult, error)
+
+type jobManager struct {
+	mu    sync.Mutex
+	jobs  map[string]*embedJob
+	embed embedFunc
+}
+
+func newJobManager() *jobManager {
+	return &jobManager{
+		jobs:  make(map[string]*embedJob),
+		embed: services.EmbedFilesWithContext,
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// start runs an embed in the background. Only one job may run at a time because each embed
+// rebuilds the whole collection.
+// The job logs through logger, tagged with its ID, so its lines can be traced back to the request
+// that started it.
+func (jm *jobManager) start(logger *slog.Logger, kb string, dataDir string, chunkSize int, progress services.ProgressCallback, onFinish func(EmbedJobStatus)) (*embedJob, error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	for _, job := range jm.jobs {
+		if job.status.Status == JobRunning {
+			return nil, fmt.Errorf("embed job %s is already running", job.status.JobID)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &embedJob{
+		status: EmbedJobStatus{
+			JobID:     newJobID(),
+			Status:    JobRunning,
+			KB:        kb,
+			DataDir:   dataDir,
+			ChunkSize: chunkSize,
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	jm.jobs[job.status.JobID] = job
+	jm.prune()
+
+	logger = logger.With("job_id", job.status.JobID)
+	logger.Info("Embed job started", "kb", kb, "dataDir", dataDir, "chunkSize", chunkSize)
+
+	go func() {
+		defer close(job.done)
+		defer cancel()
+
+		result, err := jm.embed(ctx, kb, dataDir, chunkSize, func(current, total int, filename string) {
+			jm.mu.Lock()
+			job.status.Current = current
+			job.status.Total = total
+			job.status.CurrentFile = filename
+			jm.mu.Unlock()
+
+			if progress != nil {
+				progress(current, total, filename)
+			}
+		}, func() bool {
+			// Once the index is being replaced the job can no longer be cancelled, so a cancel
+			// either wins before this point or is rejected
+			jm.mu.Lock()
+			defer jm.mu.Unlock()
+			if ctx.Err() != nil {
+				return false
+			}
+			job.status.Writing = true
+			return true
+		})
+
+		jm.mu.Lock()
+		job.status.Result = result
+		job.status.FinishedAt = time.Now()
+		job.status.Writing = false
+		switch {
+		case err == nil:
+			job.status.Status = JobCompleted
+		case errors.Is(err, context.Canceled):
+			job.status.Status = JobCancelled
+		default:
+			job.status.Status = JobFailed
+			job.status.Error = err.Error()
+		}
+		status := job.status
+		jm.mu.Unlock()
+
+		logger.Info("Embed job finished", "status", status.Status, "error", status.Error)
+
+		if onFinish != nil {
+			onFinish(status)
+		}
+	}()
+
+	return job, nil
+}
+
+// prune forgets the oldest finished jobs; callers must hold jm.mu
+func (jm *jobManager) prune() {
+	var finished []*embedJob
+	for _, job := range jm.jobs {
+		if job.status.Status != JobRunning {
+			finished = append(finished, job)
+		}
+	}
+
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].status.StartedAt.Before(finished[j].status.StartedAt)
+	})
+
+	for _, job := range finished[:len(finished)-maxFinishedJobs] {
+		delete(jm.jobs, job.status.JobID)
+	}
+}
+
+func (jm *jobManager) get(jobID string) (EmbedJobStatus, bool) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	job, ok := jm.jobs[jobID]
+	if !ok {
+		return EmbedJobStatus{}, false
+	}
+	return job.status, true
+}
+
+// list returns every known job, most recent first
+func (jm *jobManager) list() []EmbedJobStatus {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	statuses := make([]EmbedJobStatus, 0, len(jm.jobs))
+	for _, job := range jm.jobs {
+		statuses = append(statuses, job.status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].StartedAt.After(statuses[j].StartedAt)
+	})
+
+	return statuses
+}
+
+// cancel stops a running job and waits for it to finish. A job already replacing the index cannot
+// be cancelled, because the old index is gone by then.
+func (jm *jobManager) cancel(jobID string) (EmbedJobStatus, error) {
+	jm.mu.Lock()
+	job, ok := jm.jobs[jobID]
+	if !ok {
+		jm.mu.Unlock()
+		return EmbedJobStatus{}, fmt.Errorf("no embed job with ID %s", jobID)
+	}
+	if job.status.Writing {
+		status := job.status
+		jm.mu.Unlock()
+		return status, fmt.Errorf("embed job %s is already replacing the index and can no longer be cancelled", jobID)
+	}
+	// Cancelling under the lock means beginWrite sees it, so the index is left unchanged
+	job.cancel()
+	jm.mu.Unlock()
+
+	<-job.done
+
+	status, _ := jm.get(jobID)
+	return status, nil
+}
+
+// progressNotifier sends MCP progress notifications for a request's progress token, at most every
+// progressInterval apart except for the final file. The token is only valid while the request is in
+// flight, so the request must call stop before it returns.
+func progressNotifier(logger *slog.Logger, session *mcp.ServerSession, token any) (notify services.ProgressCallback, stop func()) {
+	if session == nil || token == nil {
+		return nil, func() {}
+	}
+
+	var lastSent time.Time
+	var stopped atomic.Bool
+
+	return func(current, total int, filename string) {
+		if stopped.Load() {
+			return
+		}
+		now := time.Now()
+		if now.Sub(lastSent) < progressInterval && current != total {
+			return
+		}
+		lastSent = now
+
+		err := session.NotifyProgress(context.Background(), &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      float64(current),
+			Total:         float64(total),
+			Message:       fmt.Sprintf("Embedding %s", filename),
+		})
+		if err != nil {
+			logger.Warn("Failed to send embed progress notification", "error", err)
+		}
+	}, func() { stopped.Store(true) }
+}
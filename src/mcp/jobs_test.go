@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+func TestCancelDuringWriteIsRejected(t *testing.T) {
+	writing := make(chan struct{})
+	release := make(chan struct{})
+
+	jobs := newJobManager()
+	jobs.embed = func(ctx context.Context, kb string, dataDir string, chunkSize int, progress services.ProgressCallback, beginWrite func() bool) (*services.EmbedResult, error) {
+		if !beginWrite() {
+			return &services.EmbedResult{Success: false}, context.Canceled
+		}
+		close(writing)
+		<-release
+		return &services.EmbedResult{Success: true}, nil
+	}
+
+	finished := make(chan EmbedJobStatus, 1)
+	job, err := jobs.start(slog.New(slog.NewTextHandler(io.Discard, nil)), "", "/data", 1000, nil, func(status EmbedJobStatus) {
+		finished <- status
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-writing
+	status, err := jobs.cancel(job.status.JobID)
+	if err == nil {
+		t.Fatalf("cancel() during the write succeeded with status %q", status.Status)
+	}
+	if !status.Writing {
+		t.Errorf("cancel() status.Writing = false, want true")
+	}
+
+	close(release)
+	if status := <-finished; status.Status != JobCompleted {
+		t.Errorf("job status = %q, want %q", status.Status, JobCompleted)
+	}
+}
+
+func TestCancelBeforeWrite(t *testing.T) {
+	embedding := make(chan struct{})
+	wrote := false
+
+	jobs := newJobManager()
+	jobs.embed = func(ctx context.Context, kb string, dataDir string, chunkSize int, progress services.ProgressCallback, beginWrite func() bool) (*services.EmbedResult, error) {
+		close(embedding)
+		<-ctx.Done()
+		if beginWrite() {
+			wrote = true
+			return &services.EmbedResult{Success: true}, nil
+		}
+		return &services.EmbedResult{Success: false}, ctx.Err()
+	}
+
+	job, err := jobs.start(slog.New(slog.NewTextHandler(io.Discard, nil)), "", "/data", 1000, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-embedding
+	status, err := jobs.cancel(job.status.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != JobCancelled || wrote {
+		t.Errorf("status = %q, wrote = %v, want %q without writing", status.Status, wrote, JobCancelled)
+	}
+}
@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rhydianjenkins/seek/src/logging"
+)
+
+// withRequestLogger gives every request a logger tagged with a request ID, the session and, for tool
+// calls, the tool name, so all the log lines of one call can be found together
+func withRequestLogger(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if strings.HasPrefix(method, "notifications/") {
+			return next(ctx, method, req)
+		}
+
+		logger := slog.Default().With("request_id", logging.NewRequestID(), "method", method)
+		if sessionID := req.GetSession().ID(); sessionID != "" {
+			logger = logger.With("session_id", sessionID)
+		}
+		if call, ok := req.(*mcp.CallToolRequest); ok {
+			logger = logger.With("tool", call.Params.Name)
+		}
+
+		start := time.Now()
+		result, err := next(logging.WithLogger(ctx, logger), method, req)
+		if err != nil {
+			logger.Warn("Request failed", "duration", time.Since(start), "error", err)
+		} else {
+			logger.Debug("Request completed", "duration", time.Since(start))
+		}
+
+		return result, err
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rhydianjenkins/seek/src/config"
+	"github.com/rhydianjenkins/seek/src/metrics"
+	"github.com/rhydianjenkins/seek/src/ollama"
+)
+
+const readyTimeout = 5 * time.Second
+
+// recordToolMetrics counts tool calls and their latency by tool name and outcome
+func recordToolMetrics(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		callReq, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok {
+			return next(ctx, method, req)
+		}
+
+		tool := callReq.Params.Name
+		start := time.Now()
+		result, err := next(ctx, method, req)
+		metrics.ToolDuration.ObserveSince(start, tool)
+
+		status := "ok"
+		if toolResult, isToolResult := result.(*mcp.CallToolResult); err != nil || (isToolResult && toolResult.IsError) {
+			status = "error"
+		}
+		metrics.ToolCalls.Inc(tool, status)
+
+		return result, err
+	}
+}
+
+// updateCollectionMetrics refreshes the collection gauges before each scrape
+func (rs *MCPServer) updateCollectionMetrics() {
+	status, err := rs.storage.GetStatus()
+	if err != nil {
+		slog.Error("Failed to read collection status for metrics", "error", err)
+		return
+	}
+
+	metrics.CollectionPoints.Set(float64(status.VectorCount))
+
+	if indexedAt, err := time.Parse(time.RFC3339, status.IndexedAt); err == nil {
+		metrics.LastIndexTime.Set(float64(indexedAt.Unix()))
+	}
+}
+
+// handleReady reports whether Qdrant and Ollama are reachable
+func (rs *MCPServer) handleReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	result := ReadyResult{Ready: true, Checks: map[string]string{}}
+	check := func(name string, err error) {
+		if err != nil {
+			result.Ready = false
+			result.Checks[name] = err.Error()
+			return
+		}
+		result.Checks[name] = "ok"
+	}
+
+	check("qdrant", rs.storage.Ping(ctx))
+	check("ollama", ollama.NewClient(config.Get().OllamaURL, "").Ping(ctx))
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(result)
+}
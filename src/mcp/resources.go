@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rhydianjenkins/seek/src/logging"
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+const (
+	documentURIPrefix   = "seek://doc/"
+	documentURITemplate = "seek://doc/{+filename}"
+	resourcePageSize    = 100
+)
+
+// documentURI escapes each path segment so filenames with spaces or '#' survive the round trip
+func documentURI(filename string) string {
+	segments := strings.Split(filename, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return documentURIPrefix + strings.Join(segments, "/")
+}
+
+func filenameFromURI(uri string) (string, error) {
+	if !strings.HasPrefix(uri, documentURIPrefix) {
+		return "", fmt.Errorf("not a document URI: %s", uri)
+	}
+	return url.PathUnescape(strings.TrimPrefix(uri, documentURIPrefix))
+}
+
+func (rs *MCPServer) registerResources() {
+	rs.mcpServer.AddResourceTemplate(
+		&mcp.ResourceTemplate{
+			Name:        "document",
+			Title:       "Indexed document",
+			Description: "The full text of a document in the knowledge base, with all chunks in order.",
+			URITemplate: documentURITemplate,
+			MIMEType:    "text/plain",
+		},
+		rs.handleReadDocumentResource,
+	)
+
+	if err := rs.refreshResources(context.Background()); err != nil {
+		slog.Error("Unable to list documents as resources", "error", err)
+	}
+}
+
+// refreshResources syncs the registered resources with the index. Adding or removing resources
+// sends a list_changed notification, and subscribers of documents that are still indexed are told
+// they may have been updated.
+func (rs *MCPServer) refreshResources(ctx context.Context) error {
+	result, err := services.ListDocuments(services.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	rs.resourcesMu.Lock()
+	defer rs.resourcesMu.Unlock()
+
+	current := make(map[string]bool, len(result.Documents))
+	for _, document := range result.Documents {
+		uri := documentURI(document.Filename)
+		current[uri] = true
+
+		description := fmt.Sprintf("%d chunks", document.ChunkCount)
+		if document.ModifiedAt != "" {
+			description += ", modified " + document.ModifiedAt
+		}
+
+		rs.mcpServer.AddResource(&mcp.Resource{
+			Name:        document.Filename,
+			URI:         uri,
+			Description: description,
+			MIMEType:    "text/plain",
+		}, rs.handleReadDocumentResource)
+	}
+
+	var removed []string
+	for uri := range rs.resourceURIs {
+		if !current[uri] {
+			removed = append(removed, uri)
+			continue
+		}
+		rs.mcpServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+	}
+	rs.mcpServer.RemoveResources(removed...)
+
+	rs.resourceURIs = current
+	slog.Info("Registered document resources", "count", len(current), "removed", len(removed))
+
+	return nil
+}
+
+func (rs *MCPServer) handleReadDocumentResource(
+	ctx context.Context,
+	req *mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	filename, err := filenameFromURI(req.Params.URI)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	logger := logging.FromContext(ctx).With("filename", filename)
+	logger.Info("Document resource read")
+
+	result, err := services.GetDocumentByFilename(filename)
+	if err != nil {
+		logger.Error("Document resource error", "error", err)
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      req.Params.URI,
+				MIMEType: "text/plain",
+				Text:     result.FullText,
+			},
+		},
+	}, nil
+}
+
+func (rs *MCPServer) handleSubscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	if !strings.HasPrefix(req.Params.URI, documentURIPrefix) {
+		return fmt.Errorf("cannot subscribe to %s: only document resources support subscriptions", req.Params.URI)
+	}
+	return nil
+}
+
+func (rs *MCPServer) handleUnsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package mcp
+
+import "testing"
+
+func TestDocumentURIRoundTrip(t *testing.T) {
+	tests := []struct {
+		filename string
+		uri      string
+	}{
+		{"README.md", "seek://doc/README.md"},
+		{"docs/api/spec.md", "seek://doc/docs/api/spec.md"},
+		{"emails/Weekly update #3.txt", "seek://doc/emails/Weekly%20update%20%233.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			uri := documentURI(tt.filename)
+			if uri != tt.uri {
+				t.Errorf("documentURI(%q) = %q, want %q", tt.filename, uri, tt.uri)
+			}
+
+			filename, err := filenameFromURI(uri)
+			if err != nil || filename != tt.filename {
+				t.Errorf("filenameFromURI(%q) = %q, %v, want %q", uri, filename, err, tt.filename)
+			}
+		})
+	}
+
+	if _, err := filenameFromURI("file:///etc/passwd"); err == nil {
+		t.Errorf("filenameFromURI() accepted a URI outside seek://doc/")
+	}
+}
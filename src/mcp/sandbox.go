@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rhydianjenkins/seek/src/logging"
+)
+
+// canonicalPath resolves dir to an absolute path with no symlinks or ".." elements
+func canonicalPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+
+	return resolved, nil
+}
+
+func canonicalRoots(roots []string) ([]string, error) {
+	canonical := make([]string, 0, len(roots))
+	for _, root := range roots {
+		resolved, err := canonicalPath(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid embed root %s: %w", root, err)
+		}
+		canonical = append(canonical, resolved)
+	}
+	return canonical, nil
+}
+
+// withinRoots reports whether the canonical path is one of the roots or inside one of them
+func withinRoots(path string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientRoots asks the client for its file roots. Clients without roots support yield none.
+func clientRoots(ctx context.Context, session *mcp.ServerSession) []string {
+	if session == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		return nil
+	}
+
+	var roots []string
+	for _, root := range result.Roots {
+		uri, err := url.Parse(root.URI)
+		if err != nil || uri.Scheme != "file" {
+			continue
+		}
+
+		resolved, err := canonicalPath(uri.Path)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Ignoring client root", "root", root.URI, "error", err)
+			continue
+		}
+		roots = append(roots, resolved)
+	}
+
+	return roots
+}
+
+// sandboxEmbedDir checks that dataDir lies inside the configured embed roots and, when the client
+// shares roots, inside those too. Clients declare whatever roots they like, so their roots only
+// narrow the server's and embedding is refused when the server has none.
+func (rs *MCPServer) sandboxEmbedDir(ctx context.Context, session *mcp.ServerSession, dataDir string) (string, error) {
+	if len(rs.embedRoots) == 0 {
+		return "", fmt.Errorf("no embed roots are configured; start the server with --embed-root to allow embedding")
+	}
+	if dataDir == "" {
+		return "", fmt.Errorf("dataDir is required")
+	}
+
+	resolved, err := canonicalPath(dataDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid dataDir %s: %w", dataDir, err)
+	}
+
+	if !withinRoots(resolved, rs.embedRoots) {
+		return "", fmt.Errorf("dataDir %s is outside the allowed embed roots", dataDir)
+	}
+
+	roots := clientRoots(ctx, session)
+	if len(roots) > 0 && !withinRoots(resolved, roots) {
+		return "", fmt.Errorf("dataDir %s is outside the client's roots", dataDir)
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSandboxEmbedDir(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "docs")
+	outside := filepath.Join(base, "secrets")
+
+	for _, dir := range []string{filepath.Join(root, "team"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	roots, err := canonicalRoots([]string{root})
+	if err != nil {
+		t.Fatalf("canonicalRoots() error = %v", err)
+	}
+	rs := &MCPServer{embedRoots: roots}
+
+	tests := []struct {
+		name    string
+		dataDir string
+		allowed bool
+	}{
+		{"root itself", root, true},
+		{"subdirectory", filepath.Join(root, "team"), true},
+		{"dot dot escape", filepath.Join(root, "team", "..", "..", "secrets"), false},
+		{"symlink escape", filepath.Join(root, "escape"), false},
+		{"outside", outside, false},
+		{"missing", filepath.Join(root, "missing"), false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rs.sandboxEmbedDir(context.Background(), nil, tt.dataDir)
+			if (err == nil) != tt.allowed {
+				t.Errorf("sandboxEmbedDir(%s) error = %v, allowed = %v", tt.dataDir, err, tt.allowed)
+			}
+		})
+	}
+
+	unconfigured := &MCPServer{}
+	if _, err := unconfigured.sandboxEmbedDir(context.Background(), nil, root); err == nil {
+		t.Errorf("sandboxEmbedDir() without server embed roots should be rejected")
+	}
+}
@@ -1,27 +1,146 @@
 package mcp
 
 import (
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rhydianjenkins/seek/src/db"
+	"github.com/rhydianjenkins/seek/src/services"
 )
 
 type MCPServer struct {
 	mcpServer *mcp.Server
 	storage   *db.Storage
+
+	resourcesMu  sync.Mutex
+	resourceURIs map[string]bool
+
+	inFlight atomic.Int64
+
+	embedRoots    []string
+	embedDisabled bool
+	jobs          *jobManager
+}
+
+type ServerOptions struct {
+	EmbedRoots   []string
+	DisableEmbed bool
+}
+
+type HTTPOptions struct {
+	Bind            string
+	Port            int
+	Transport       string
+	TokensFile      string
+	TLSCertFile     string
+	TLSKeyFile      string
+	SessionTimeout  time.Duration
+	ShutdownTimeout time.Duration
+}
+
+type APIToken struct {
+	Name   string
+	Token  string
+	Scopes []string
 }
 
 type SearchToolInput struct {
-	Query string `json:"query" jsonschema:"required" jsonschema_description:"Search query text"`
-	Limit int    `json:"limit" jsonschema_description:"Maximum number of results to return (default: 3)"`
+	Query    string   `json:"query" jsonschema:"required" jsonschema_description:"Search query text"`
+	Limit    int      `json:"limit" jsonschema_description:"Maximum number of results to return (default: 3)"`
+	Offset   int      `json:"offset,omitempty" jsonschema_description:"Number of results to skip; pass the previous response's next_offset to get the next page (default: 0)"`
+	MinScore float32  `json:"minScore,omitempty" jsonschema_description:"Drop results whose similarity score is below this, between 0 and 1 (default: 0)"`
+	KB       string   `json:"kb,omitempty" jsonschema_description:"Knowledge base to search (default: the server's configured one)"`
+	KBs      []string `json:"kbs,omitempty" jsonschema_description:"Search several knowledge bases at once, merging their results by normalised score; overrides kb"`
+
+	Diversity float64 `json:"diversity,omitempty" jsonschema_description:"Between 0 and 1: prefer results that differ from each other over the most similar ones (default: 0)"`
+	MaxPerDoc int     `json:"maxPerDoc,omitempty" jsonschema_description:"Maximum number of results from any one document (default: no cap)"`
+	Expand    string  `json:"expand,omitempty" jsonschema_description:"Also search for rewordings of the query (paraphrase) or a hypothetical answer to it (hyde), written by the server's chat model, fusing the rankings (default: off)"`
+	GroupBy   string  `json:"groupBy,omitempty" jsonschema_description:"Set to file to return the best matching documents, each with its closest chunks and total score, instead of chunks; limit then counts documents (default: off)"`
+	Context   int     `json:"context,omitempty" jsonschema_description:"Include this many neighbouring chunks either side of each result, merging results that overlap (default: 0)"`
 }
 
 type EmbedToolInput struct {
 	DataDir   string `json:"dataDir" jsonschema:"required" jsonschema_description:"Directory containing .txt files to embed"`
 	ChunkSize int    `json:"chunkSize" jsonschema_description:"Maximum chunk size in characters for splitting text (default: 1000)"`
+	Wait      bool   `json:"wait" jsonschema_description:"Wait for the embed job to finish, sending progress notifications, instead of returning its job ID straight away (default: false)"`
+	KB        string `json:"kb,omitempty" jsonschema_description:"Knowledge base to embed into, replacing its contents (default: the server's configured one)"`
+}
+
+type EmbedStatusToolInput struct {
+	JobID string `json:"jobId" jsonschema_description:"ID of the embed job to check; omit to list all recent jobs"`
+}
+
+type EmbedCancelToolInput struct {
+	JobID string `json:"jobId" jsonschema:"required" jsonschema_description:"ID of the embed job to cancel"`
 }
 
-type StatusToolInput struct{}
+type EmbedJobStatus struct {
+	JobID       string `json:"job_id"`
+	Status      string `json:"status"`
+	KB          string `json:"kb,omitempty"`
+	DataDir     string `json:"data_dir"`
+	ChunkSize   int    `json:"chunk_size"`
+	Current     int    `json:"current"`
+	Total       int    `json:"total"`
+	CurrentFile string `json:"current_file,omitempty"`
+	// Writing is set while the job replaces the index, when it can no longer be cancelled
+	Writing    bool                  `json:"writing,omitempty"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt time.Time             `json:"finished_at,omitzero"`
+	Result     *services.EmbedResult `json:"result,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+type EmbedJobList struct {
+	Jobs []EmbedJobStatus `json:"jobs"`
+}
+
+type StatusToolInput struct {
+	KB string `json:"kb,omitempty" jsonschema_description:"Knowledge base to report on (default: the server's configured one)"`
+}
 
 type GetDocumentToolInput struct {
 	Filename string `json:"filename" jsonschema:"required" jsonschema_description:"The filename of the document to retrieve"`
+	KB       string `json:"kb,omitempty" jsonschema_description:"Knowledge base holding the document (default: the server's configured one)"`
+	From     *int64 `json:"from,omitempty" jsonschema_description:"First chunk index to return, for reading large documents in parts (default: the first chunk)"`
+	To       *int64 `json:"to,omitempty" jsonschema_description:"Last chunk index to return, inclusive (default: the last chunk)"`
+}
+
+type GrepToolInput struct {
+	Pattern    string `json:"pattern" jsonschema:"required" jsonschema_description:"Regular expression (Go syntax) to match against each line of the indexed text"`
+	IgnoreCase bool   `json:"ignoreCase,omitempty" jsonschema_description:"Ignore case when matching (default: false)"`
+	Word       bool   `json:"word,omitempty" jsonschema_description:"Only match whole words (default: false)"`
+	Limit      int    `json:"limit" jsonschema_description:"Maximum number of matching lines to return (default: 100)"`
+	KB         string `json:"kb,omitempty" jsonschema_description:"Knowledge base to search (default: the server's configured one)"`
+}
+
+type FindSimilarToolInput struct {
+	Filename string `json:"filename" jsonschema:"required" jsonschema_description:"The filename of the document to find similar documents for"`
+	Chunk    *int64 `json:"chunk,omitempty" jsonschema_description:"Only use this chunk of the document, by chunk index (default: the whole document)"`
+	Limit    int    `json:"limit" jsonschema_description:"Maximum number of documents to return (default: 5)"`
+	KB       string `json:"kb,omitempty" jsonschema_description:"Knowledge base holding the document (default: the server's configured one)"`
+}
+
+type AskToolInput struct {
+	Question string `json:"question" jsonschema:"required" jsonschema_description:"The question to answer from the knowledge base"`
+}
+
+type ListDocumentsToolInput struct {
+	Prefix  string `json:"prefix" jsonschema_description:"Only list documents whose filename starts with this prefix"`
+	Pattern string `json:"pattern" jsonschema_description:"Only list documents matching this glob, e.g. *.pdf or docs/*.md"`
+	Type    string `json:"type,omitempty" jsonschema_description:"Only list documents of this file type, e.g. pdf or md"`
+	Sort    string `json:"sort,omitempty" jsonschema_description:"Sort by name (default), size, chunks, modified or indexed; all but name list the largest or newest first"`
+	Reverse bool   `json:"reverse,omitempty" jsonschema_description:"Reverse the sort order"`
+	Offset  int    `json:"offset" jsonschema_description:"Number of documents to skip, for paging (default: 0)"`
+	Limit   int    `json:"limit" jsonschema_description:"Maximum number of documents to return (default: 100)"`
+	KB      string `json:"kb,omitempty" jsonschema_description:"Knowledge base to list (default: the server's configured one)"`
+}
+
+type ListKBsToolInput struct{}
+
+type ReadyResult struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
 }
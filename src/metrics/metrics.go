@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBuckets suits calls that take anywhere from milliseconds (Qdrant) to tens of seconds (chat models)
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		desc:   desc{name: name, help: help, labels: labels},
+		values: make(map[string]*sample),
+	}
+	r.register(counter)
+	return counter
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	hist := &HistogramVec{
+		desc:    desc{name: name, help: help, labels: labels},
+		buckets: buckets,
+		values:  make(map[string]*histogram),
+	}
+	r.register(hist)
+	return hist
+}
+
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	gauge := &Gauge{
+		desc: desc{name: name, help: help},
+	}
+	r.register(gauge)
+	return gauge
+}
+
+// OnScrape registers a hook that runs before every scrape, for gauges that are read on demand
+func (r *Registry) OnScrape(hook func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onScrape = append(r.onScrape, hook)
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes every metric in the Prometheus text exposition format
+func (r *Registry) Write(out io.Writer) error {
+	r.mu.Lock()
+	hooks := append([]func(){}, r.onScrape...)
+	collectors := append([]collector{}, r.collectors...)
+	r.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	w := &expositionWriter{}
+	for _, c := range collectors {
+		c.write(w)
+	}
+
+	_, err := io.WriteString(out, w.String())
+	return err
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.values[key]
+	if !ok {
+		s = &sample{labelValues: labelValues}
+		c.values[key] = s
+	}
+	s.value += delta
+}
+
+func (c *CounterVec) write(w *expositionWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w.header(c.desc, "counter")
+	for _, key := range sortedKeys(c.values) {
+		s := c.values[key]
+		w.sample(c.name, c.labels, s.labelValues, nil, s.value)
+	}
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist, ok := h.values[key]
+	if !ok {
+		hist = &histogram{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hist
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			hist.counts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += value
+}
+
+// ObserveSince records the seconds elapsed since start
+func (h *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) write(w *expositionWriter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w.header(h.desc, "histogram")
+	for _, key := range sortedKeys(h.values) {
+		hist := h.values[key]
+		for i, bound := range h.buckets {
+			w.sample(h.name+"_bucket", h.labels, hist.labelValues, []string{"le", formatFloat(bound)}, float64(hist.counts[i]))
+		}
+		w.sample(h.name+"_bucket", h.labels, hist.labelValues, []string{"le", "+Inf"}, float64(hist.count))
+		w.sample(h.name+"_sum", h.labels, hist.labelValues, nil, hist.sum)
+		w.sample(h.name+"_count", h.labels, hist.labelValues, nil, float64(hist.count))
+	}
+}
+
+func (g *Gauge) Set(value float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = value
+	g.set = true
+}
+
+func (g *Gauge) write(w *expositionWriter) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	w.header(g.desc, "gauge")
+	if g.set {
+		w.sample(g.name, nil, nil, nil, g.value)
+	}
+}
+
+type expositionWriter struct {
+	strings.Builder
+}
+
+func (w *expositionWriter) header(d desc, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, d.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, kind)
+}
+
+func (w *expositionWriter) sample(name string, labels, labelValues, extra []string, value float64) {
+	var pairs []string
+	for i, label := range labels {
+		if i < len(labelValues) {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", label, labelValues[i]))
+		}
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extra[0], extra[1]))
+	}
+
+	if len(pairs) > 0 {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+	} else {
+		fmt.Fprintf(w, "%s %s\n", name, formatFloat(value))
+	}
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
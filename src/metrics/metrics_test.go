@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	registry := NewRegistry()
+
+	calls := registry.NewCounterVec("test_calls_total", "Calls.", "tool", "status")
+	calls.Inc("search", "ok")
+	calls.Inc("search", "ok")
+	calls.Inc("embed", "error")
+
+	duration := registry.NewHistogramVec("test_duration_seconds", "Duration.", []float64{0.1, 1}, "tool")
+	duration.Observe(0.05, "search")
+	duration.Observe(0.5, "search")
+
+	points := registry.NewGauge("test_points", "Points.")
+	registry.OnScrape(func() {
+		points.Set(42)
+	})
+
+	var out strings.Builder
+	if err := registry.Write(&out); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	expected := []string{
+		"# TYPE test_calls_total counter",
+		`test_calls_total{tool="embed",status="error"} 1`,
+		`test_calls_total{tool="search",status="ok"} 2`,
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{tool="search",le="0.1"} 1`,
+		`test_duration_seconds_bucket{tool="search",le="1"} 2`,
+		`test_duration_seconds_bucket{tool="search",le="+Inf"} 2`,
+		`test_duration_seconds_sum{tool="search"} 0.55`,
+		`test_duration_seconds_count{tool="search"} 2`,
+		"# TYPE test_points gauge",
+		"test_points 42",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("output is missing %q:\n%s", line, out.String())
+		}
+	}
+}
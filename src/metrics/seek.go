@@ -0,0 +1,46 @@
+package metrics
+
+// Default is the registry served on /metrics
+var Default = NewRegistry()
+
+var (
+	ToolCalls = Default.NewCounterVec(
+		"seek_mcp_tool_calls_total",
+		"MCP tool calls by tool and outcome.",
+		"tool", "status",
+	)
+	ToolDuration = Default.NewHistogramVec(
+		"seek_mcp_tool_duration_seconds",
+		"Time taken to handle MCP tool calls.",
+		DefaultBuckets,
+		"tool",
+	)
+
+	EmbeddingRequests = Default.NewCounterVec(
+		"seek_ollama_embedding_requests_total",
+		"Embedding requests sent to Ollama by outcome.",
+		"status",
+	)
+	EmbeddingDuration = Default.NewHistogramVec(
+		"seek_ollama_embedding_duration_seconds",
+		"Time taken by Ollama embedding requests by outcome, including failures and timeouts.",
+		DefaultBuckets,
+		"status",
+	)
+
+	QdrantDuration = Default.NewHistogramVec(
+		"seek_qdrant_query_duration_seconds",
+		"Time taken by Qdrant queries by operation.",
+		DefaultBuckets,
+		"operation",
+	)
+
+	CollectionPoints = Default.NewGauge(
+		"seek_collection_points",
+		"Number of points stored in the collection.",
+	)
+	LastIndexTime = Default.NewGauge(
+		"seek_last_index_timestamp_seconds",
+		"Unix time of the last successful index build.",
+	)
+)
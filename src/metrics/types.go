@@ -0,0 +1,51 @@
+package metrics
+
+import "sync"
+
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*sample
+}
+
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+type Gauge struct {
+	desc
+	mu    sync.Mutex
+	value float64
+	set   bool
+}
+
+type sample struct {
+	labelValues []string
+	value       float64
+}
+
+type histogram struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+type collector interface {
+	write(w *expositionWriter)
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+	onScrape   []func()
+}
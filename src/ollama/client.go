@@ -3,13 +3,18 @@ package ollama
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 )
 
+// ErrUnavailable is returned when the Ollama server cannot be reached
+var ErrUnavailable = errors.New("ollama unavailable")
+
 func NewClient(baseURL, model string) *Client {
 	return &Client{
 		baseURL: baseURL,
@@ -17,7 +22,29 @@ func NewClient(baseURL, model string) *Client {
 	}
 }
 
-func (c *Client) Chat(messages []Message, tools []Tool, onChunk func(string)) (*Message, error) {
+// Ping checks that the Ollama server is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/version", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Chat sends messages to the chat model, streaming its reply to onChunk. Cancelling ctx stops the
+// request, and Chat then returns ctx's error.
+func (c *Client) Chat(ctx context.Context, messages []Message, tools []Tool, onChunk func(string)) (*Message, error) {
 	request := chatRequest{
 		Model:    c.model,
 		Messages: messages,
@@ -30,13 +57,18 @@ func (c *Client) Chat(messages []Message, tools []Tool, onChunk func(string)) (*
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := http.Post(
-		c.baseURL+"/api/chat",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
@@ -81,6 +113,9 @@ func (c *Client) Chat(messages []Message, tools []Tool, onChunk func(string)) (*
 		}
 	}
 
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("error reading response: %w", err)
 	}
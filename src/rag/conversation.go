@@ -0,0 +1,129 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rhydianjenkins/seek/src/config"
+	"github.com/rhydianjenkins/seek/src/ollama"
+	"github.com/rhydianjenkins/seek/src/services"
+	"github.com/rhydianjenkins/seek/src/tools"
+)
+
+const systemPrompt = "You are a helpful assistant with access to a knowledge base. " +
+	"When answering questions, you can use the search tool to find relevant information. " +
+	"Tool results are numbered like [1], [2]. Cite the sources that support each statement " +
+	"using those numbers in square brackets, for example [1] or [2][3]. " +
+	"Only cite numbers that appear in the tool results."
+
+const maxIterations = 10
+
+// NewConversation starts a conversation, continuing from a previous history and its citations when given
+func NewConversation(history []ollama.Message, citations []services.Citation) (*Conversation, error) {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil, fmt.Errorf("config not initialized")
+	}
+
+	conv := &Conversation{
+		client:  ollama.NewClient(cfg.OllamaURL, cfg.ChatModel),
+		sources: tools.NewSources(),
+	}
+
+	if len(history) > 0 {
+		conv.messages = history
+		conv.sources = tools.NewSourcesFrom(citations)
+	} else {
+		conv.messages = []ollama.Message{
+			{
+				Role:    "system",
+				Content: systemPrompt,
+			},
+		}
+	}
+
+	return conv, nil
+}
+
+// Ask answers a single question against the knowledge base without any history
+func Ask(ctx context.Context, question string) (*services.AskResult, error) {
+	conv, err := NewConversation(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return conv.Ask(ctx, question)
+}
+
+// Ask runs the tool loop for one question and returns the final answer with the sources it cites.
+// Cancelling ctx stops the loop and any chat request in progress.
+func (conv *Conversation) Ask(ctx context.Context, question string) (*services.AskResult, error) {
+	conv.messages = append(conv.messages, ollama.Message{
+		Role:    "user",
+		Content: question,
+	})
+
+	availableTools := tools.GetTools()
+	answer := ""
+
+	for range maxIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		response, err := conv.client.Chat(ctx, conv.messages, availableTools, conv.OnChunk)
+		if err != nil {
+			return nil, fmt.Errorf("Chat request failed: %w", err)
+		}
+
+		conv.messages = append(conv.messages, *response)
+
+		if len(response.ToolCalls) > 0 {
+			for _, toolCall := range response.ToolCalls {
+				if conv.OnToolCall != nil {
+					conv.OnToolCall(toolCall)
+				}
+
+				result, err := tools.ExecuteTool(toolCall, conv.sources)
+				if err != nil {
+					return nil, fmt.Errorf("Tool execution failed: %w", err)
+				}
+
+				conv.messages = append(conv.messages, ollama.Message{
+					Role:     "tool",
+					Content:  result,
+					ToolName: toolCall.Function.Name,
+				})
+			}
+
+			// Continue to next iteration to get LLM's response with tool results
+			continue
+		}
+
+		// No tool calls means we got the final answer, so finish
+		answer = response.Content
+		break
+	}
+
+	citations, invalid := conv.sources.Resolve(answer)
+	if citations == nil {
+		citations = []services.Citation{}
+	}
+
+	return &services.AskResult{
+		Question:         question,
+		Answer:           answer,
+		Citations:        citations,
+		InvalidCitations: invalid,
+	}, nil
+}
+
+// Messages returns the full history, including tool calls and their results
+func (conv *Conversation) Messages() []ollama.Message {
+	return conv.messages
+}
+
+// Sources returns every chunk retrieved so far, in citation order
+func (conv *Conversation) Sources() []services.Citation {
+	return conv.sources.All()
+}
@@ -0,0 +1,16 @@
+package rag
+
+import (
+	"github.com/rhydianjenkins/seek/src/ollama"
+	"github.com/rhydianjenkins/seek/src/tools"
+)
+
+type Conversation struct {
+	client   *ollama.Client
+	messages []ollama.Message
+	sources  *tools.Sources
+
+	// OnChunk receives the answer as it streams in, and OnToolCall each tool call before it runs
+	OnChunk    func(string)
+	OnToolCall func(ollama.ToolCall)
+}
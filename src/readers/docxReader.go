@@ -4,7 +4,7 @@ import (
 	"archive/zip"
 	"encoding/xml"
 	"io"
-	"log"
+	"log/slog"
 	"strings"
 )
 
@@ -33,7 +33,7 @@ type wordRun struct {
 func (r DOCXReader) Read(path string) string {
 	zipReader, err := zip.OpenReader(path)
 	if err != nil {
-		log.Printf("Error opening DOCX %s: %v", path, err)
+		slog.Error("Failed to open DOCX", "path", path, "error", err)
 		return ""
 	}
 	defer zipReader.Close()
@@ -43,14 +43,14 @@ func (r DOCXReader) Read(path string) string {
 		if file.Name == "word/document.xml" {
 			rc, err := file.Open()
 			if err != nil {
-				log.Printf("Error opening document.xml in %s: %v", path, err)
+				slog.Error("Failed to open document.xml", "path", path, "error", err)
 				return ""
 			}
 			defer rc.Close()
 
 			content, err := io.ReadAll(rc)
 			if err != nil {
-				log.Printf("Error reading document.xml in %s: %v", path, err)
+				slog.Error("Failed to read document.xml", "path", path, "error", err)
 				return ""
 			}
 			documentXML = string(content)
@@ -59,13 +59,13 @@ func (r DOCXReader) Read(path string) string {
 	}
 
 	if documentXML == "" {
-		log.Printf("No document.xml found in %s", path)
+		slog.Warn("No document.xml found", "path", path)
 		return ""
 	}
 
 	var doc wordDocument
 	if err := xml.Unmarshal([]byte(documentXML), &doc); err != nil {
-		log.Printf("Error parsing XML in %s: %v", path, err)
+		slog.Error("Failed to parse XML", "path", path, "error", err)
 		return ""
 	}
 
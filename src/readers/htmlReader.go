@@ -1,7 +1,7 @@
 package readers
 
 import (
-	"log"
+	"log/slog"
 	"os"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
@@ -15,7 +15,7 @@ var _ FileReader = HTMLReader{}
 func (r HTMLReader) Read(path string) string {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
-		log.Printf("Error reading HTML file %s: %v", path, err)
+		slog.Error("Failed to read HTML file", "path", path, "error", err)
 		return ""
 	}
 
@@ -29,7 +29,7 @@ func (r HTMLReader) Read(path string) string {
 
 	markdown, err := converter.ConvertString(string(bytes))
 	if err != nil {
-		log.Printf("Error converting HTML to markdown %s: %v", path, err)
+		slog.Error("Failed to convert HTML to markdown", "path", path, "error", err)
 		return ""
 	}
 
@@ -1,7 +1,7 @@
 package readers
 
 import (
-	"log"
+	"log/slog"
 	"strings"
 
 	"github.com/ledongthuc/pdf"
@@ -15,7 +15,7 @@ var _ FileReader = PDFReader{}
 func (r PDFReader) Read(path string) string {
 	f, pdfReader, err := pdf.Open(path)
 	if err != nil {
-		log.Printf("Error opening PDF %s: %v", path, err)
+		slog.Error("Failed to open PDF", "path", path, "error", err)
 		return ""
 	}
 	defer f.Close()
@@ -31,7 +31,7 @@ func (r PDFReader) Read(path string) string {
 
 		content, err := p.GetPlainText(nil)
 		if err != nil {
-			log.Printf("Error reading page %d of %s: %v", pageNum, path, err)
+			slog.Error("Failed to read PDF page", "path", path, "page", pageNum, "error", err)
 			continue
 		}
 		text.WriteString(content)
@@ -1,7 +1,7 @@
 package readers
 
 import (
-	"log"
+	"log/slog"
 	"os"
 	"unicode/utf8"
 )
@@ -14,7 +14,7 @@ var _ FileReader = PlainTextReader{}
 func (r PlainTextReader) Read(path string) string {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
-		log.Printf("Error reading file %s: %v", path, err)
+		slog.Error("Failed to read file", "path", path, "error", err)
 		return ""
 	}
 	content := string(bytes)
@@ -23,6 +23,6 @@ func (r PlainTextReader) Read(path string) string {
 		return content
 	}
 
-	log.Println("Warning: invalid UTF-8 content in file", path)
+	slog.Warn("Invalid UTF-8 content in file", "path", path)
 	return ""
 }
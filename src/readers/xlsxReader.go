@@ -1,7 +1,7 @@
 package readers
 
 import (
-	"log"
+	"log/slog"
 	"strings"
 
 	"github.com/xuri/excelize/v2"
@@ -15,12 +15,12 @@ var _ FileReader = XLSXReader{}
 func (r XLSXReader) Read(path string) string {
 	f, err := excelize.OpenFile(path)
 	if err != nil {
-		log.Printf("Error opening XLSX %s: %v", path, err)
+		slog.Error("Failed to open XLSX", "path", path, "error", err)
 		return ""
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
-			log.Printf("Error closing XLSX %s: %v", path, err)
+			slog.Error("Failed to close XLSX", "path", path, "error", err)
 		}
 	}()
 
@@ -30,7 +30,7 @@ func (r XLSXReader) Read(path string) string {
 	for _, sheetName := range sheets {
 		rows, err := f.GetRows(sheetName)
 		if err != nil {
-			log.Printf("Error reading sheet %s in %s: %v", sheetName, path, err)
+			slog.Error("Failed to read XLSX sheet", "path", path, "sheet", sheetName, "error", err)
 			continue
 		}
 
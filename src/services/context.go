@@ -0,0 +1,87 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/rhydianjenkins/seek/src/db"
+)
+
+// addContext replaces each result's content with the chunks up to n either side of it
+func addContext(storage *db.Storage, defaultKB string, results []SearchResult, n int) ([]SearchResult, error) {
+	windows := contextWindows(results, n)
+
+	for i := range windows {
+		window := &windows[i]
+		kb := window.KB
+		if kb == "" {
+			kb = defaultKB
+		}
+
+		points, err := storage.ForCollection(kb).GetChunks(window.Filename, window.Context.From, window.Context.To)
+		if err != nil {
+			return nil, err
+		}
+
+		slices.SortFunc(points, func(a, b *qdrant.RetrievedPoint) int {
+			return cmp.Compare(a.Payload["chunk_index"].GetIntegerValue(), b.Payload["chunk_index"].GetIntegerValue())
+		})
+
+		contents := make([]string, 0, len(points))
+		for _, point := range points {
+			contents = append(contents, point.Payload["content"].GetStringValue())
+		}
+		if len(contents) > 0 {
+			window.Content = strings.Join(contents, "\n\n")
+		}
+
+		// The window may run past either end of the document
+		if len(points) > 0 {
+			window.Context.From = points[0].Payload["chunk_index"].GetIntegerValue()
+			window.Context.To = points[len(points)-1].Payload["chunk_index"].GetIntegerValue()
+		}
+	}
+
+	return windows, nil
+}
+
+// contextWindows gives each result a window of n chunks either side. Windows in the same document
+// that overlap or touch are merged into the earlier, better scoring, result.
+func contextWindows(results []SearchResult, n int) []SearchResult {
+	windows := make([]SearchResult, 0, len(results))
+	for _, result := range results {
+		result.Context = &ChunkRange{From: max(result.ChunkIndex-int64(n), 0), To: result.ChunkIndex + int64(n)}
+		result.MatchedChunks = []int64{result.ChunkIndex}
+		windows = append(windows, result)
+	}
+
+	// Merging can make a window touch another, so repeat until nothing changes
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(windows) && !merged; i++ {
+			for j := i + 1; j < len(windows); j++ {
+				if !windowsTouch(windows[i], windows[j]) {
+					continue
+				}
+
+				windows[i].Context = &ChunkRange{
+					From: min(windows[i].Context.From, windows[j].Context.From),
+					To:   max(windows[i].Context.To, windows[j].Context.To),
+				}
+				windows[i].MatchedChunks = append(windows[i].MatchedChunks, windows[j].MatchedChunks...)
+				slices.Sort(windows[i].MatchedChunks)
+				windows = slices.Delete(windows, j, j+1)
+				merged = true
+				break
+			}
+		}
+	}
+
+	return windows
+}
+
+func windowsTouch(a, b SearchResult) bool {
+	return documentKey(a) == documentKey(b) && a.Context.From <= b.Context.To+1 && b.Context.From <= a.Context.To+1
+}
@@ -0,0 +1,85 @@
+package services
+
+import "math"
+
+const (
+	// candidateFactor is how many candidates per requested result are fetched for diversifying
+	candidateFactor = 4
+	minCandidates   = 20
+)
+
+// diversify picks up to limit results from candidates, which are sorted by score. With diversity
+// above 0 each pick maximises Maximal Marginal Relevance: (1-diversity) times its relevance minus
+// diversity times its greatest similarity to a result already picked. Relevance is the score
+// divided by the top score, so it is on the same 0-1 scale as similarity whether scores are
+// similarities or fused ranks. With maxPerDoc above 0 no document contributes more than maxPerDoc
+// results.
+func diversify(candidates []SearchResult, limit int, diversity float64, maxPerDoc int) []SearchResult {
+	selected := make([]SearchResult, 0, min(limit, len(candidates)))
+	perDoc := map[string]int{}
+	remaining := append([]SearchResult(nil), candidates...)
+
+	var top float64
+	for _, candidate := range candidates {
+		top = max(top, float64(candidate.Score))
+	}
+
+	for len(selected) < limit && len(remaining) > 0 {
+		best := -1
+		bestValue := math.Inf(-1)
+
+		for i, candidate := range remaining {
+			if maxPerDoc > 0 && perDoc[documentKey(candidate)] >= maxPerDoc {
+				continue
+			}
+
+			value := float64(candidate.Score)
+			if diversity > 0 {
+				if top > 0 {
+					value /= top
+				}
+				var similarity float64
+				for _, picked := range selected {
+					similarity = max(similarity, cosine(candidate.vector, picked.vector))
+				}
+				value = (1-diversity)*value - diversity*similarity
+			}
+
+			if value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+
+		if best < 0 {
+			break
+		}
+
+		perDoc[documentKey(remaining[best])]++
+		selected = append(selected, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+
+	return selected
+}
+
+func documentKey(result SearchResult) string {
+	return result.KB + "\x00" + result.Filename
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
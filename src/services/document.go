@@ -1,68 +1,195 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
+	"github.com/qdrant/go-client/qdrant"
 	"github.com/rhydianjenkins/seek/src/db"
 )
 
-// GetDocumentByFilename retrieves a full document by filename from the knowledge base
+// maxSuggestions is how many similar filenames are suggested when a document is not found
+const maxSuggestions = 5
+
+// DocumentNotFoundError is returned when no document has the filename, with the closest names
+type DocumentNotFoundError struct {
+	Filename    string
+	Suggestions []string
+}
+
+func (e *DocumentNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("%v: no document with filename %s", ErrNotFound, e.Filename)
+	}
+	return fmt.Sprintf("%v: no document with filename %s; did you mean %s?", ErrNotFound, e.Filename, strings.Join(e.Suggestions, ", "))
+}
+
+func (e *DocumentNotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// GetDocumentByFilename retrieves a full document by filename from the configured knowledge base
 func GetDocumentByFilename(filename string) (*DocumentResult, error) {
-	storage, err := db.Connect()
+	return GetDocument(DocumentOptions{Filename: filename})
+}
+
+// GetDocument retrieves a document, or a range of its chunks, from the named knowledge base
+func GetDocument(opts DocumentOptions) (*DocumentResult, error) {
+	chunks := []DocumentChunk{}
+	err := StreamDocument(opts, func(chunk DocumentChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
 	if err != nil {
-		return &DocumentResult{
+		result := &DocumentResult{
 			Success: false,
-			Error:   fmt.Sprintf("Unable to connect to storage: %v", err),
-		}, err
+			Error:   err.Error(),
+		}
+		var notFound *DocumentNotFoundError
+		if errors.As(err, &notFound) {
+			result.Suggestions = notFound.Suggestions
+		}
+		return result, err
+	}
+
+	contents := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		contents[i] = chunk.Content
 	}
 
-	points, err := storage.GetDocumentByFilename(filename)
+	return &DocumentResult{
+		Success:    true,
+		Filename:   opts.Filename,
+		ChunkCount: len(chunks),
+		Chunks:     chunks,
+		FullText:   strings.Join(contents, "\n\n"),
+	}, nil
+}
+
+// StreamDocument calls visit with each chunk of a document in order, so huge documents can be
+// written out without holding them in memory. When no document has the filename the error is a
+// *DocumentNotFoundError suggesting the closest names.
+func StreamDocument(opts DocumentOptions, visit func(DocumentChunk) error) error {
+	if err := ValidateKB(opts.KB); err != nil {
+		return err
+	}
+	if opts.Filename == "" {
+		return fmt.Errorf("%w: filename cannot be empty", ErrInvalidInput)
+	}
+	if (opts.From != nil && *opts.From < 0) || (opts.To != nil && *opts.To < 0) {
+		return fmt.Errorf("%w: chunk range cannot be negative", ErrInvalidInput)
+	}
+	if opts.From != nil && opts.To != nil && *opts.From > *opts.To {
+		return fmt.Errorf("%w: chunk range starts after it ends (%d > %d)", ErrInvalidInput, *opts.From, *opts.To)
+	}
+
+	storage, err := db.ConnectTo(opts.KB)
 	if err != nil {
-		return &DocumentResult{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to retrieve document: %v", err),
-		}, err
+		return fmt.Errorf("unable to connect to storage: %w", err)
 	}
 
-	if len(points) == 0 {
-		return &DocumentResult{
-			Success: false,
-			Error:   fmt.Sprintf("No document found with filename: %s", filename),
-		}, fmt.Errorf("no document found with filename: %s", filename)
+	visited := 0
+	err = storage.ScanDocument(opts.Filename, opts.From, opts.To, func(point *qdrant.RetrievedPoint) error {
+		visited++
+		return visit(DocumentChunk{
+			ChunkIndex: point.Payload["chunk_index"].GetIntegerValue(),
+			Content:    point.Payload["content"].GetStringValue(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve document: %w", err)
+	}
+	if visited > 0 {
+		return nil
 	}
 
-	chunks := make([]DocumentChunk, 0, len(points))
-	for _, point := range points {
-		chunk := DocumentChunk{}
-		if point.Payload != nil {
-			if content, ok := point.Payload["content"]; ok {
-				chunk.Content = content.GetStringValue()
-			}
-			if chunkIdx, ok := point.Payload["chunk_index"]; ok {
-				chunk.ChunkIndex = chunkIdx.GetIntegerValue()
-			}
+	// Nothing was found: either the chunk range is empty or there is no such document
+	if opts.From != nil || opts.To != nil {
+		count, err := storage.CountChunks(opts.Filename, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve document: %w", err)
 		}
-		chunks = append(chunks, chunk)
+		if count > 0 {
+			return fmt.Errorf("%w: %s has no chunks in the range given (it has %d chunks)", ErrInvalidInput, opts.Filename, count)
+		}
+	}
+
+	documents, err := storage.ListDocuments()
+	if err != nil {
+		return &DocumentNotFoundError{Filename: opts.Filename}
+	}
+	names := make([]string, len(documents))
+	for i, document := range documents {
+		names[i] = document.Filename
+	}
+
+	return &DocumentNotFoundError{Filename: opts.Filename, Suggestions: suggestFilenames(opts.Filename, names, maxSuggestions)}
+}
+
+// suggestFilenames returns up to limit names close to filename, closest first. A name is close
+// when it contains filename, or its base name, ignoring case, or is a few edits away from it.
+func suggestFilenames(filename string, names []string, limit int) []string {
+	target := strings.ToLower(filename)
+	base := target[strings.LastIndex(target, "/")+1:]
+
+	type candidate struct {
+		name     string
+		distance int
 	}
+	var candidates []candidate
+
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		distance := editDistance(target, lower)
 
-	sort.Slice(chunks, func(i, j int) bool {
-		return chunks[i].ChunkIndex < chunks[j].ChunkIndex
+		switch {
+		case lower == target:
+			distance = 0
+		case strings.Contains(lower, target) || strings.Contains(lower, base):
+			// Finding what was typed inside a longer name beats a name that merely looks similar
+			distance = min(distance, 1)
+		case distance > max(2, len(target)/3):
+			continue
+		}
+		candidates = append(candidates, candidate{name, distance})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
 	})
 
-	fullContent := ""
-	for _, chunk := range chunks {
-		if fullContent != "" {
-			fullContent += "\n\n"
+	suggestions := make([]string, 0, min(limit, len(candidates)))
+	for _, candidate := range candidates[:min(limit, len(candidates))] {
+		suggestions = append(suggestions, candidate.name)
+	}
+	return suggestions
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
 		}
-		fullContent += chunk.Content
+		previous, current = current, previous
 	}
 
-	return &DocumentResult{
-		Success:    true,
-		Filename:   filename,
-		ChunkCount: len(chunks),
-		Chunks:     chunks,
-		FullText:   fullContent,
-	}, nil
+	return previous[len(br)]
 }
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSuggestFilenames(t *testing.T) {
+	names := []string{
+		"docs/architecture.md",
+		"docs/deployment.md",
+		"manuals/Router-Manual.pdf",
+		"README.md",
+		"notes/readme-old.md",
+	}
+
+	tests := []struct {
+		filename string
+		want     []string
+	}{
+		{"architecture.md", []string{"docs/architecture.md"}},
+		{"docs/deploymnet.md", []string{"docs/deployment.md"}},
+		{"router-manual.pdf", []string{"manuals/Router-Manual.pdf"}},
+		{"readme.md", []string{"README.md"}},
+		{"invoices.xlsx", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := suggestFilenames(tt.filename, names, maxSuggestions); !slices.Equal(got, tt.want) {
+				t.Errorf("suggestFilenames(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestFilenamesLimit(t *testing.T) {
+	names := []string{"a/report.txt", "b/report.txt", "c/report.txt"}
+
+	got := suggestFilenames("report.txt", names, 2)
+	if want := []string{"a/report.txt", "b/report.txt"}; !slices.Equal(got, want) {
+		t.Errorf("suggestFilenames() = %q, want %q", got, want)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"deployment", "deploymnet", 2},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
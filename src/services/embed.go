@@ -1,11 +1,13 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/qdrant/go-client/qdrant"
 	"github.com/rhydianjenkins/seek/src/db"
@@ -43,11 +45,38 @@ func chunkText(text string, maxChunkSize int) []string {
 	return chunks
 }
 
-func readTextFiles(dataDir string) (map[string]string, error) {
-	files := make(map[string]string)
+func fileType(path string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if ext == "" {
+		return "text"
+	}
+	return ext
+}
+
+func symlinkWithin(path, root string) bool {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func readTextFiles(dataDir string) (map[string]sourceFile, error) {
+	files := make(map[string]sourceFile)
 	reader := readers.NewReader()
 
-	err := filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+	root, err := filepath.EvalSymlinks(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -56,10 +85,26 @@ func readTextFiles(dataDir string) (map[string]string, error) {
 			return nil
 		}
 
+		// Never follow a symlink out of the data directory
+		if info.Mode()&os.ModeSymlink != 0 && !symlinkWithin(path, root) {
+			slog.Warn("Skipping symlink that points outside the data directory", "path", path, "dataDir", dataDir)
+			return nil
+		}
+
 		content := reader.ReadFile(path)
 
 		relPath, _ := filepath.Rel(dataDir, path)
-		files[relPath] = content
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			absPath = path
+		}
+		files[relPath] = sourceFile{
+			path:     absPath,
+			content:  content,
+			size:     info.Size(),
+			fileType: fileType(path),
+			modTime:  info.ModTime(),
+		}
 
 		return nil
 	})
@@ -69,7 +114,37 @@ func readTextFiles(dataDir string) (map[string]string, error) {
 
 // EmbedFilesWithProgress generates embeddings for files with optional progress callback
 func EmbedFilesWithProgress(dataDir string, chunkSize int, progressCallback ProgressCallback) (*EmbedResult, error) {
-	storage, err := db.Connect()
+	return EmbedFilesWithContext(context.Background(), "", dataDir, chunkSize, progressCallback, nil)
+}
+
+// EmbedFilesWithContext generates embeddings for files into the named knowledge base (empty for the
+// configured one), stopping early if ctx is cancelled.
+// The existing index is only replaced once every chunk has been embedded, so a cancelled run leaves it untouched.
+// beginWrite, when set, is called just before the index is replaced; returning false abandons the
+// embed as cancelled, so a caller can stop cancellation racing the write.
+func EmbedFilesWithContext(ctx context.Context, kb string, dataDir string, chunkSize int, progressCallback ProgressCallback, beginWrite func() bool) (*EmbedResult, error) {
+	if chunkSize <= 0 {
+		return &EmbedResult{
+			Success: false,
+			Error:   fmt.Sprintf("Chunk size must be positive, got %d", chunkSize),
+		}, fmt.Errorf("%w: chunk size must be positive, got %d", ErrInvalidInput, chunkSize)
+	}
+
+	if err := ValidateKB(kb); err != nil {
+		return &EmbedResult{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+
+	if info, err := os.Stat(dataDir); err != nil || !info.IsDir() {
+		return &EmbedResult{
+			Success: false,
+			Error:   fmt.Sprintf("%s is not a directory", dataDir),
+		}, fmt.Errorf("%w: %s is not a directory", ErrInvalidInput, dataDir)
+	}
+
+	storage, err := db.ConnectTo(kb)
 	if err != nil {
 		return &EmbedResult{
 			Success: false,
@@ -89,27 +164,52 @@ func EmbedFilesWithProgress(dataDir string, chunkSize int, progressCallback Prog
 		return &EmbedResult{
 			Success: false,
 			Error:   fmt.Sprintf("No supported files found in %s", dataDir),
-		}, fmt.Errorf("no supported files found in %s", dataDir)
+		}, fmt.Errorf("%w: no supported files found in %s", ErrInvalidInput, dataDir)
 	}
 
 	var points []*qdrant.PointStruct
+	var documents []db.DocumentInfo
 	pointID := uint64(1)
 
 	totalFiles := len(files)
 	currentFile := 0
 
-	for filename, content := range files {
+	for filename, file := range files {
+		if err := ctx.Err(); err != nil {
+			return &EmbedResult{
+				Success: false,
+				Error:   "Embedding cancelled",
+			}, err
+		}
+
 		currentFile++
 		if progressCallback != nil {
 			progressCallback(currentFile, totalFiles, filename)
 		}
 
-		chunks := chunkText(content, chunkSize)
+		chunks := chunkText(file.content, chunkSize)
+		document := db.DocumentInfo{
+			Filename:   filename,
+			Size:       file.size,
+			FileType:   file.fileType,
+			Source:     file.path,
+			ModifiedAt: file.modTime.UTC().Format(time.RFC3339),
+		}
 
 		for chunkIdx, chunk := range chunks {
-			embedding, err := storage.GetEmbedding(chunk)
+			if ctx.Err() != nil {
+				break
+			}
+
+			embedding, err := storage.EmbedDocument(chunk)
+			if IsUnavailable(err) {
+				return &EmbedResult{
+					Success: false,
+					Error:   fmt.Sprintf("Unable to generate embeddings: %v", err),
+				}, err
+			}
 			if err != nil {
-				log.Printf("Error generating embedding for %s chunk %d: %v", filename, chunkIdx, err)
+				slog.Error("Failed to generate embedding", "filename", filename, "chunk", chunkIdx, "error", err)
 				continue
 			}
 
@@ -117,6 +217,9 @@ func EmbedFilesWithProgress(dataDir string, chunkSize int, progressCallback Prog
 				"filename":    filename,
 				"chunk_index": chunkIdx,
 				"content":     chunk,
+				"size":        file.size,
+				"file_type":   file.fileType,
+				"mtime":       document.ModifiedAt,
 			}
 
 			point := &qdrant.PointStruct{
@@ -127,9 +230,22 @@ func EmbedFilesWithProgress(dataDir string, chunkSize int, progressCallback Prog
 
 			points = append(points, point)
 			pointID++
+			document.ChunkCount++
+		}
+
+		// Only documents with an embedded chunk can be searched or read back
+		if document.ChunkCount > 0 {
+			documents = append(documents, document)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return &EmbedResult{
+			Success: false,
+			Error:   "Embedding cancelled",
+		}, err
+	}
+
 	if len(points) == 0 {
 		return &EmbedResult{
 			Success: false,
@@ -137,7 +253,14 @@ func EmbedFilesWithProgress(dataDir string, chunkSize int, progressCallback Prog
 		}, fmt.Errorf("no points to index")
 	}
 
-	err = storage.GenerateDb(points)
+	if beginWrite != nil && !beginWrite() {
+		return &EmbedResult{
+			Success: false,
+			Error:   "Embedding cancelled",
+		}, context.Canceled
+	}
+
+	err = storage.GenerateDb(points, documents)
 	if err != nil {
 		return &EmbedResult{
 			Success: false,
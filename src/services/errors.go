@@ -0,0 +1,21 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/rhydianjenkins/seek/src/db"
+	"github.com/rhydianjenkins/seek/src/ollama"
+)
+
+// Errors returned by the services can be matched with errors.Is to decide how to react
+var (
+	// ErrNotFound means the collection or the requested document does not exist
+	ErrNotFound = db.ErrNotFound
+	// ErrInvalidInput means the request itself was wrong, such as a non-positive limit
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// IsUnavailable reports whether err was caused by Qdrant or Ollama being unreachable
+func IsUnavailable(err error) bool {
+	return errors.Is(err, db.ErrUnavailable) || errors.Is(err, ollama.ErrUnavailable)
+}
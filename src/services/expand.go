@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rhydianjenkins/seek/src/config"
+	"github.com/rhydianjenkins/seek/src/ollama"
+)
+
+const (
+	// paraphraseCount is how many rewordings of the query are asked for
+	paraphraseCount = 3
+	// rrfK dampens the advantage of the very top ranks in reciprocal rank fusion
+	rrfK = 60
+)
+
+const paraphrasePrompt = `Write %d different search queries that could find documents answering the query below. Use different wording and synonyms from the original. Reply with one query per line and nothing else.
+
+Query: %s`
+
+const hydePrompt = `Write a short passage, of at most a paragraph, that could appear in a document answering the query below. Reply with the passage only.
+
+Query: %s`
+
+var listMarker = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s*`)
+
+// searchQuery is one text to embed for a search
+type searchQuery struct {
+	Text string
+	// AsDocument embeds the text as a document rather than a query, for hypothetical answers
+	AsDocument bool
+}
+
+// expandQuery asks the chat model for alternative texts to search for alongside query
+func expandQuery(mode ExpandMode, query string) ([]searchQuery, error) {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil, fmt.Errorf("config not initialized")
+	}
+	client := ollama.NewClient(cfg.OllamaURL, cfg.ChatModel)
+
+	prompt := fmt.Sprintf(hydePrompt, query)
+	if mode == ExpandParaphrase {
+		prompt = fmt.Sprintf(paraphrasePrompt, paraphraseCount, query)
+	}
+
+	reply, err := client.Chat(context.Background(), []ollama.Message{{Role: "user", Content: prompt}}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand query: %w", err)
+	}
+
+	if mode == ExpandHyDE {
+		passage := strings.TrimSpace(reply.Content)
+		if passage == "" {
+			return nil, nil
+		}
+		return []searchQuery{{Text: passage, AsDocument: true}}, nil
+	}
+
+	paraphrases := parseParaphrases(reply.Content, query, paraphraseCount)
+	queries := make([]searchQuery, len(paraphrases))
+	for i, paraphrase := range paraphrases {
+		queries[i] = searchQuery{Text: paraphrase}
+	}
+	return queries, nil
+}
+
+// parseParaphrases takes up to limit queries from a reply with one per line, dropping list
+// markers, quotes, blank lines, repeats and the original query
+func parseParaphrases(reply string, original string, limit int) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(original)): true}
+	var paraphrases []string
+
+	for _, line := range strings.Split(reply, "\n") {
+		line = listMarker.ReplaceAllString(line, "")
+		line = strings.Trim(strings.TrimSpace(line), `"'`)
+		if line == "" || seen[strings.ToLower(line)] {
+			continue
+		}
+
+		seen[strings.ToLower(line)] = true
+		paraphrases = append(paraphrases, line)
+		if len(paraphrases) == limit {
+			break
+		}
+	}
+
+	return paraphrases
+}
+
+// fuseRanks combines ranked lists with reciprocal rank fusion: each chunk scores the sum of
+// 1/(rrfK + rank) over the lists it appears in, so chunks found by several queries rise to the top
+func fuseRanks(lists [][]SearchResult) []SearchResult {
+	fused := map[string]*SearchResult{}
+	var order []string
+
+	for _, list := range lists {
+		for rank, result := range list {
+			key := fmt.Sprintf("%s\x00%d", documentKey(result), result.ChunkIndex)
+			existing, ok := fused[key]
+			if !ok {
+				result.Score = 0
+				existing = &result
+				fused[key] = existing
+				order = append(order, key)
+			}
+			existing.Score += 1 / float32(rrfK+rank+1)
+		}
+	}
+
+	results := make([]SearchResult, 0, len(order))
+	for _, key := range order {
+		results = append(results, *fused[key])
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseParaphrases(t *testing.T) {
+	reply := `1. API request quotas
+2) "throttling limits"
+
+- rate limits
+* How many requests can I make per minute?
+* API request quotas
+- Fifth line`
+
+	got := parseParaphrases(reply, "Rate limits", 3)
+	want := []string{"API request quotas", "throttling limits", "How many requests can I make per minute?"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseParaphrases() = %q, want %q", got, want)
+	}
+}
+
+func TestFuseRanks(t *testing.T) {
+	lists := [][]SearchResult{
+		{
+			{Filename: "a.md", ChunkIndex: 0, Score: 0.9},
+			{Filename: "b.md", ChunkIndex: 0, Score: 0.8},
+		},
+		{
+			{Filename: "b.md", ChunkIndex: 0, Score: 0.7},
+			{Filename: "c.md", ChunkIndex: 2, Score: 0.6},
+		},
+	}
+
+	fused := fuseRanks(lists)
+
+	if got, want := filenames(fused), []string{"b.md", "a.md", "c.md"}; !slices.Equal(got, want) {
+		t.Fatalf("fuseRanks() = %v, want %v", got, want)
+	}
+	if want := float32(1.0/62 + 1.0/61); fused[0].Score != want {
+		t.Errorf("fused score of b.md = %v, want %v", fused[0].Score, want)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/rhydianjenkins/seek/src/db"
+)
+
+// Grep finds the lines of indexed chunks that match a regular expression. The full-text index
+// narrows down the chunks to check when the pattern contains whole words; otherwise every chunk
+// is checked. Scanning stops once there are more matches than the limit, so matches are sorted by
+// file, chunk and line, but a truncated result holds the first found rather than the first in
+// that order.
+func Grep(opts GrepOptions) (*GrepResults, error) {
+	if err := ValidateKB(opts.KB); err != nil {
+		return &GrepResults{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+	if opts.Pattern == "" {
+		return &GrepResults{
+			Success: false,
+			Error:   "Pattern cannot be empty",
+		}, fmt.Errorf("%w: pattern cannot be empty", ErrInvalidInput)
+	}
+	if opts.Limit <= 0 {
+		return &GrepResults{
+			Success: false,
+			Error:   fmt.Sprintf("Limit must be positive, got %d", opts.Limit),
+		}, fmt.Errorf("%w: limit must be positive, got %d", ErrInvalidInput, opts.Limit)
+	}
+
+	pattern := opts.Pattern
+	if opts.Word {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if opts.IgnoreCase {
+		pattern = `(?i)` + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return &GrepResults{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid pattern %q: %v", opts.Pattern, err),
+		}, fmt.Errorf("%w: pattern %q: %v", ErrInvalidInput, opts.Pattern, err)
+	}
+	// Compile has already accepted the pattern, so parsing it again cannot fail
+	parsed, _ := syntax.Parse(pattern, syntax.Perl)
+
+	storage, err := db.ConnectTo(opts.KB)
+	if err != nil {
+		return &GrepResults{
+			Success: false,
+			Error:   fmt.Sprintf("Unable to connect to storage: %v", err),
+		}, err
+	}
+
+	terms := grepTerms(parsed)
+	if terms != nil {
+		indexed, err := storage.HasTextIndex()
+		if err != nil {
+			return &GrepResults{
+				Success: false,
+				Error:   fmt.Sprintf("Unable to check the full-text index: %v", err),
+			}, err
+		}
+		// Grep only reads, so a missing index is worked around rather than built here
+		if !indexed {
+			slog.Warn("No full-text index, checking every chunk; run 'seek kb upgrade' to build it", "collection", storage.CollectionName())
+			terms = nil
+		}
+	}
+
+	// One match past the limit shows the result is truncated without reading the rest
+	matches := []GrepMatch{}
+	err = storage.ScanText(terms, func(point *qdrant.RetrievedPoint) bool {
+		filename := point.Payload["filename"].GetStringValue()
+		chunkIndex := point.Payload["chunk_index"].GetIntegerValue()
+
+		for i, line := range strings.Split(point.Payload["content"].GetStringValue(), "\n") {
+			if re.MatchString(line) {
+				matches = append(matches, GrepMatch{Filename: filename, ChunkIndex: chunkIndex, Line: i + 1, Text: line})
+				if len(matches) > opts.Limit {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return &GrepResults{
+			Success: false,
+			Error:   fmt.Sprintf("Grep failed: %v", err),
+		}, err
+	}
+
+	slices.SortFunc(matches, func(a, b GrepMatch) int {
+		return cmp.Or(cmp.Compare(a.Filename, b.Filename), cmp.Compare(a.ChunkIndex, b.ChunkIndex), cmp.Compare(a.Line, b.Line))
+	})
+
+	results := &GrepResults{
+		Success: true,
+		Pattern: opts.Pattern,
+		Matches: matches,
+	}
+	if len(matches) > opts.Limit {
+		results.Matches = matches[:opts.Limit]
+		results.Truncated = true
+	}
+	results.Count = len(results.Matches)
+
+	return results, nil
+}
+
+// grepTerms returns texts for the full-text index, one per alternative of the pattern, such that
+// every match contains all the words of one of them. It returns nil when some alternative has no
+// whole word to look for, so every chunk must be checked.
+func grepTerms(re *syntax.Regexp) []string {
+	re = uncapture(re)
+	alternatives := []*syntax.Regexp{re}
+	if re.Op == syntax.OpAlternate {
+		alternatives = re.Sub
+	}
+
+	terms := make([]string, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		words := requiredWords(alternative)
+		if len(words) == 0 {
+			return nil
+		}
+		// The index is lowercased, and so are the words looked up in it
+		terms = append(terms, strings.ToLower(strings.Join(words, " ")))
+	}
+	return terms
+}
+
+// requiredWords returns the whole words in the literal parts of re. The index only matches whole
+// words, so a word at the edge of a literal is only used when a boundary or anchor is next to it.
+func requiredWords(re *syntax.Regexp) []string {
+	re = uncapture(re)
+	parts := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		parts = re.Sub
+	}
+
+	bounded := func(i int) bool {
+		if i < 0 || i >= len(parts) {
+			return false
+		}
+		switch parts[i].Op {
+		case syntax.OpWordBoundary, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+			return true
+		}
+		return false
+	}
+
+	var words []string
+	for i, part := range parts {
+		if part.Op == syntax.OpLiteral {
+			words = append(words, wholeWords(string(part.Rune), bounded(i-1), bounded(i+1))...)
+		}
+	}
+	return words
+}
+
+// wholeWords splits a literal on whitespace, dropping its first and last words unless they are
+// bounded, as they may be parts of longer words
+func wholeWords(literal string, boundedBefore, boundedAfter bool) []string {
+	words := strings.Fields(literal)
+	if len(words) == 0 {
+		return nil
+	}
+
+	if first, _ := utf8.DecodeRuneInString(literal); !boundedBefore && !unicode.IsSpace(first) {
+		words = words[1:]
+	}
+	if last, _ := utf8.DecodeLastRuneInString(literal); len(words) > 0 && !boundedAfter && !unicode.IsSpace(last) {
+		words = words[:len(words)-1]
+	}
+	return words
+}
+
+func uncapture(re *syntax.Regexp) *syntax.Regexp {
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	return re
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"regexp/syntax"
+	"slices"
+	"testing"
+)
+
+func TestGrepTerms(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		// A bare literal may be part of a longer word, so the index cannot be used
+		{"ERR_4091", nil},
+		{`\bERR_4091\b`, []string{"err_4091"}},
+		{`(?i)\b(?:timeout)\b`, []string{"timeout"}},
+		{"connection refused by upstream", []string{"refused by"}},
+		{`^deploy failed: .*`, []string{"deploy failed:"}},
+		{`\bfoo\b|\bbar baz\b`, []string{"foo", "bar baz"}},
+		{`\bfoo\b|ba.`, nil},
+		{`[0-9]+`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := syntax.Parse(tt.pattern, syntax.Perl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := grepTerms(re); !slices.Equal(got, tt.want) {
+				t.Errorf("grepTerms(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
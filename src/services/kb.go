@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rhydianjenkins/seek/src/config"
+	"github.com/rhydianjenkins/seek/src/db"
+)
+
+// ValidateKB checks a knowledge base name; an empty name, meaning the configured one, is valid
+func ValidateKB(name string) error {
+	if name == "" {
+		return nil
+	}
+	if err := config.ValidateCollection(name); err != nil {
+		return fmt.Errorf("%w: knowledge base %v", ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// CreateKB creates an empty knowledge base
+func CreateKB(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: a knowledge base name is required", ErrInvalidInput)
+	}
+	if err := ValidateKB(name); err != nil {
+		return err
+	}
+
+	storage, err := db.ConnectTo(name)
+	if err != nil {
+		return err
+	}
+
+	status, err := storage.GetStatus()
+	if err != nil {
+		return err
+	}
+	if status.Exists {
+		return fmt.Errorf("%w: knowledge base %s already exists", ErrInvalidInput, name)
+	}
+
+	return storage.CreateCollection()
+}
+
+// DeleteKB deletes a knowledge base and everything embedded in it
+func DeleteKB(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: a knowledge base name is required", ErrInvalidInput)
+	}
+	if err := ValidateKB(name); err != nil {
+		return err
+	}
+
+	storage, err := db.ConnectTo(name)
+	if err != nil {
+		return err
+	}
+
+	status, err := storage.GetStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Exists {
+		return fmt.Errorf("%w: knowledge base %s", ErrNotFound, name)
+	}
+
+	return storage.DeleteCollection()
+}
+
+// UpgradeKB adds what newer versions of seek build at embed time to a knowledge base embedded by
+// an older one, or the configured one when name is empty, without embedding it again
+func UpgradeKB(name string) error {
+	if err := ValidateKB(name); err != nil {
+		return err
+	}
+
+	storage, err := db.ConnectTo(name)
+	if err != nil {
+		return err
+	}
+
+	status, err := storage.GetStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Exists {
+		return fmt.Errorf("%w: knowledge base %s", ErrNotFound, status.CollectionName)
+	}
+
+	if err := storage.EnsureTextIndex(); err != nil {
+		return err
+	}
+	return storage.EnsureManifest()
+}
+
+// GetKB returns the status of a knowledge base, or of the configured one when name is empty
+func GetKB(name string) (*db.CollectionStatus, error) {
+	if err := ValidateKB(name); err != nil {
+		return nil, err
+	}
+
+	storage, err := db.ConnectTo(name)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := storage.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	if !status.Exists {
+		return status, fmt.Errorf("%w: knowledge base %s", ErrNotFound, status.CollectionName)
+	}
+
+	return status, nil
+}
+
+// ListKBs lists every knowledge base on the Qdrant server, marking the configured one as default
+func ListKBs() (*KBList, error) {
+	storage, err := db.Connect()
+	if err != nil {
+		return &KBList{
+			Success: false,
+			Error:   fmt.Sprintf("Unable to connect to storage: %v", err),
+		}, err
+	}
+
+	names, err := storage.ListCollections()
+	if err != nil {
+		return &KBList{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to list knowledge bases: %v", err),
+		}, err
+	}
+	sort.Strings(names)
+
+	list := &KBList{
+		Success:        true,
+		KnowledgeBases: make([]KBInfo, 0, len(names)),
+	}
+
+	for _, name := range names {
+		status, err := storage.ForCollection(name).GetStatus()
+		if err != nil {
+			return &KBList{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to get status of %s: %v", name, err),
+			}, err
+		}
+
+		list.KnowledgeBases = append(list.KnowledgeBases, KBInfo{
+			Name:      name,
+			Points:    status.VectorCount,
+			IndexedAt: status.IndexedAt,
+			Default:   name == storage.CollectionName(),
+		})
+	}
+
+	return list, nil
+}
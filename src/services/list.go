@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/rhydianjenkins/seek/src/db"
+)
+
+// DefaultListLimit is how many documents the CLI and tools list when no limit is given
+const DefaultListLimit = 100
+
+// ListDocuments lists the indexed documents matching the options, sorted and paged by offset/limit.
+// Documents come from the knowledge base's manifest when it has one, so listing does not read any chunks.
+func ListDocuments(opts ListOptions) (*DocumentList, error) {
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return &DocumentList{
+			Success: false,
+			Error:   "Offset and limit cannot be negative",
+		}, fmt.Errorf("%w: offset and limit cannot be negative", ErrInvalidInput)
+	}
+
+	switch opts.Sort {
+	case "", SortByName, SortBySize, SortByChunks, SortByModified, SortByIndexed:
+	default:
+		return &DocumentList{
+			Success: false,
+			Error:   fmt.Sprintf("Unknown sort %q", opts.Sort),
+		}, fmt.Errorf("%w: unknown sort %q, use %s, %s, %s, %s or %s", ErrInvalidInput, opts.Sort, SortByName, SortBySize, SortByChunks, SortByModified, SortByIndexed)
+	}
+
+	if err := ValidateKB(opts.KB); err != nil {
+		return &DocumentList{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+
+	if opts.Pattern != "" {
+		if _, err := path.Match(opts.Pattern, ""); err != nil {
+			return &DocumentList{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid pattern %q: %v", opts.Pattern, err),
+			}, fmt.Errorf("%w: pattern %q: %v", ErrInvalidInput, opts.Pattern, err)
+		}
+	}
+
+	storage, err := db.ConnectTo(opts.KB)
+	if err != nil {
+		return &DocumentList{
+			Success: false,
+			Error:   fmt.Sprintf("Unable to connect to storage: %v", err),
+		}, err
+	}
+
+	status, err := storage.GetStatus()
+	if err != nil {
+		return &DocumentList{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to get database status: %v", err),
+		}, err
+	}
+
+	var documents []db.DocumentInfo
+	if status.Exists {
+		documents, err = storage.ListDocuments()
+		if err != nil {
+			return &DocumentList{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to list documents: %v", err),
+			}, err
+		}
+	}
+
+	filtered := filterDocuments(documents, opts)
+	sortDocuments(filtered, opts.Sort, opts.Reverse)
+	return pageDocuments(filtered, opts), nil
+}
+
+func filterDocuments(documents []db.DocumentInfo, opts ListOptions) []db.DocumentInfo {
+	filtered := make([]db.DocumentInfo, 0, len(documents))
+	fileType := strings.TrimPrefix(strings.ToLower(opts.Type), ".")
+
+	for _, document := range documents {
+		if opts.Prefix != "" && !strings.HasPrefix(document.Filename, opts.Prefix) {
+			continue
+		}
+		if opts.Pattern != "" && !matchesPattern(opts.Pattern, document.Filename) {
+			continue
+		}
+		if fileType != "" && document.FileType != fileType {
+			continue
+		}
+		filtered = append(filtered, document)
+	}
+
+	return filtered
+}
+
+// sortDocuments orders documents by filename, or largest or newest first, with ties by filename
+func sortDocuments(documents []db.DocumentInfo, by DocumentSort, reverse bool) {
+	before := func(a, b db.DocumentInfo) bool {
+		switch by {
+		case SortBySize:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case SortByChunks:
+			if a.ChunkCount != b.ChunkCount {
+				return a.ChunkCount > b.ChunkCount
+			}
+		case SortByModified:
+			// RFC 3339 times in UTC sort as strings
+			if a.ModifiedAt != b.ModifiedAt {
+				return a.ModifiedAt > b.ModifiedAt
+			}
+		case SortByIndexed:
+			if a.IndexedAt != b.IndexedAt {
+				return a.IndexedAt > b.IndexedAt
+			}
+		}
+		return a.Filename < b.Filename
+	}
+
+	sort.SliceStable(documents, func(i, j int) bool {
+		if reverse {
+			return before(documents[j], documents[i])
+		}
+		return before(documents[i], documents[j])
+	})
+}
+
+// matchesPattern matches a glob against the full path, or against the base name when the glob has no '/'
+func matchesPattern(pattern, filename string) bool {
+	if matched, _ := path.Match(pattern, filename); matched {
+		return true
+	}
+
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(filename))
+		return matched
+	}
+
+	return false
+}
+
+func pageDocuments(documents []db.DocumentInfo, opts ListOptions) *DocumentList {
+	total := len(documents)
+	start := min(max(opts.Offset, 0), total)
+	end := total
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, total)
+	}
+
+	list := &DocumentList{
+		Success:   true,
+		Documents: documents[start:end],
+		Total:     total,
+		Offset:    start,
+	}
+
+	if end < total {
+		list.NextOffset = end
+	}
+
+	return list
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/rhydianjenkins/seek/src/db"
+)
+
+func TestFilterAndPageDocuments(t *testing.T) {
+	documents := []db.DocumentInfo{
+		{Filename: "emails/b.txt", FileType: "txt", Size: 300, ChunkCount: 2, ModifiedAt: "2026-03-02T09:00:00Z"},
+		{Filename: "docs/guide.pdf", FileType: "pdf", Size: 9000, ChunkCount: 12, ModifiedAt: "2026-01-15T12:00:00Z"},
+		{Filename: "emails/a.txt", FileType: "txt", Size: 300, ChunkCount: 1, ModifiedAt: "2026-03-01T09:00:00Z"},
+		{Filename: "docs/api/spec.md", FileType: "md", Size: 4200, ChunkCount: 6, ModifiedAt: "2025-11-20T08:30:00Z"},
+		{Filename: "README.md", FileType: "md", Size: 1500, ChunkCount: 2, ModifiedAt: "2026-02-10T17:45:00Z"},
+	}
+
+	tests := []struct {
+		name       string
+		opts       ListOptions
+		expected   []string
+		total      int
+		nextOffset int
+	}{
+		{
+			name:     "no filters",
+			opts:     ListOptions{},
+			expected: []string{"README.md", "docs/api/spec.md", "docs/guide.pdf", "emails/a.txt", "emails/b.txt"},
+			total:    5,
+		},
+		{
+			name:     "prefix",
+			opts:     ListOptions{Prefix: "emails/"},
+			expected: []string{"emails/a.txt", "emails/b.txt"},
+			total:    2,
+		},
+		{
+			name:     "base name glob",
+			opts:     ListOptions{Pattern: "*.md"},
+			expected: []string{"README.md", "docs/api/spec.md"},
+			total:    2,
+		},
+		{
+			name:     "path glob",
+			opts:     ListOptions{Pattern: "docs/*"},
+			expected: []string{"docs/guide.pdf"},
+			total:    1,
+		},
+		{
+			name:       "first page",
+			opts:       ListOptions{Limit: 2},
+			expected:   []string{"README.md", "docs/api/spec.md"},
+			total:      5,
+			nextOffset: 2,
+		},
+		{
+			name:     "last page",
+			opts:     ListOptions{Offset: 4, Limit: 2},
+			expected: []string{"emails/b.txt"},
+			total:    5,
+		},
+		{
+			name:     "file type",
+			opts:     ListOptions{Type: ".PDF"},
+			expected: []string{"docs/guide.pdf"},
+			total:    1,
+		},
+		{
+			name:     "largest first, ties by name",
+			opts:     ListOptions{Sort: SortBySize},
+			expected: []string{"docs/guide.pdf", "docs/api/spec.md", "README.md", "emails/a.txt", "emails/b.txt"},
+			total:    5,
+		},
+		{
+			name:       "most chunks first",
+			opts:       ListOptions{Sort: SortByChunks, Limit: 2},
+			expected:   []string{"docs/guide.pdf", "docs/api/spec.md"},
+			total:      5,
+			nextOffset: 2,
+		},
+		{
+			name:     "oldest first",
+			opts:     ListOptions{Sort: SortByModified, Reverse: true, Prefix: "emails/"},
+			expected: []string{"emails/a.txt", "emails/b.txt"},
+			total:    2,
+		},
+		{
+			name:     "names in reverse",
+			opts:     ListOptions{Reverse: true, Pattern: "*.md"},
+			expected: []string{"docs/api/spec.md", "README.md"},
+			total:    2,
+		},
+		{
+			name:     "offset past the end",
+			opts:     ListOptions{Offset: 10, Limit: 2},
+			expected: []string{},
+			total:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterDocuments(documents, tt.opts)
+			sortDocuments(filtered, tt.opts.Sort, tt.opts.Reverse)
+			list := pageDocuments(filtered, tt.opts)
+
+			filenames := make([]string, 0, len(list.Documents))
+			for _, document := range list.Documents {
+				filenames = append(filenames, document.Filename)
+			}
+
+			if len(filenames) != len(tt.expected) {
+				t.Fatalf("documents = %v, want %v", filenames, tt.expected)
+			}
+			for i := range filenames {
+				if filenames[i] != tt.expected[i] {
+					t.Errorf("documents = %v, want %v", filenames, tt.expected)
+					break
+				}
+			}
+
+			if list.Total != tt.total || list.NextOffset != tt.nextOffset {
+				t.Errorf("total, next offset = %d, %d, want %d, %d", list.Total, list.NextOffset, tt.total, tt.nextOffset)
+			}
+		})
+	}
+}
@@ -2,13 +2,27 @@ package services
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/qdrant/go-client/qdrant"
 	"github.com/rhydianjenkins/seek/src/db"
 )
 
-// SearchFiles performs a semantic search on the knowledge base
+// SearchFiles performs a semantic search on the configured knowledge base
 func SearchFiles(searchTerm string, limit int) (*SearchResults, error) {
+	return Search(SearchOptions{Query: searchTerm, Limit: limit})
+}
+
+// Search performs a semantic search on one or more knowledge bases
+func Search(opts SearchOptions) (*SearchResults, error) {
+	if err := validateSearch(opts); err != nil {
+		return &SearchResults{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+
 	storage, err := db.Connect()
 	if err != nil {
 		return &SearchResults{
@@ -17,10 +31,165 @@ func SearchFiles(searchTerm string, limit int) (*SearchResults, error) {
 		}, err
 	}
 
-	// Normalize search term to lowercase for consistent embeddings
-	normalizedTerm := strings.ToLower(searchTerm)
+	kbs := opts.KBs
+	if len(kbs) == 0 {
+		kbs = []string{""}
+	}
+
+	if opts.GroupBy == GroupByFile {
+		return searchGroups(storage.ForCollection(kbs[0]), opts)
+	}
+
+	queries := []searchQuery{{Text: opts.Query}}
+	var expansions []string
+	if opts.Expand != ExpandNone {
+		expanded, err := expandQuery(opts.Expand, opts.Query)
+		if err != nil {
+			return &SearchResults{
+				Success: false,
+				Error:   fmt.Sprintf("Search failed: %v", err),
+			}, err
+		}
+		for _, query := range expanded {
+			expansions = append(expansions, query.Text)
+		}
+		queries = append(queries, expanded...)
+	}
+
+	// One more result than the page is selected to tell whether there is a next page. A single
+	// query of a single knowledge base ranked by score alone is paged by Qdrant; otherwise every
+	// result up to the page is fetched, and diversifying needs more candidates than it returns so
+	// similar ones can be passed over.
+	skip := opts.Offset
+	query := db.VectorQuery{
+		Limit:       opts.Offset + opts.Limit + 1,
+		MinScore:    opts.MinScore,
+		WithVectors: opts.Diversity > 0,
+	}
+	if len(kbs) == 1 && len(queries) == 1 && opts.Diversity == 0 && opts.MaxPerDoc == 0 {
+		query.Offset, query.Limit, skip = opts.Offset, opts.Limit+1, 0
+	} else if opts.Diversity > 0 || opts.MaxPerDoc > 0 {
+		query.Limit = max(query.Limit*candidateFactor, minCandidates)
+	}
+
+	// Each text is embedded once per distinct prefix, as knowledge bases may use different ones
+	perKB := make([][]SearchResult, 0, len(kbs))
+	embeddings := map[string][]float32{}
+	for _, kb := range kbs {
+		label := ""
+		if len(opts.KBs) > 1 {
+			label = kb
+		}
+
+		results, err := searchKB(storage.ForCollection(kb), label, queries, query, embeddings)
+		if err != nil && label != "" {
+			return &SearchResults{
+				Success: false,
+				Error:   fmt.Sprintf("Search of knowledge base %s failed: %v", kb, err),
+			}, fmt.Errorf("search of knowledge base %s failed: %w", kb, err)
+		}
+		if err != nil {
+			return &SearchResults{
+				Success: false,
+				Error:   fmt.Sprintf("Search failed: %v", err),
+			}, err
+		}
+		perKB = append(perKB, results)
+	}
+
+	candidates := perKB[0]
+	if len(perKB) > 1 {
+		candidates = mergeResults(perKB, query.Limit)
+	}
+
+	results := diversify(candidates, skip+opts.Limit+1, opts.Diversity, opts.MaxPerDoc)
+	results = results[min(skip, len(results)):]
+
+	page := &SearchResults{
+		Success:    true,
+		Query:      opts.Query,
+		KBs:        opts.KBs,
+		Expansions: expansions,
+		Offset:     opts.Offset,
+	}
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+		page.NextOffset = opts.Offset + opts.Limit
+	}
+
+	if opts.Context > 0 {
+		if results, err = addContext(storage, kbs[0], results, opts.Context); err != nil {
+			return &SearchResults{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to fetch context: %v", err),
+			}, err
+		}
+	}
+
+	page.Results = results
+	page.Count = len(results)
+	return page, nil
+}
 
-	searchResult, err := storage.Search(normalizedTerm, limit)
+func validateSearch(opts SearchOptions) error {
+	if strings.TrimSpace(opts.Query) == "" {
+		return fmt.Errorf("%w: search query cannot be empty", ErrInvalidInput)
+	}
+	if opts.Limit <= 0 {
+		return fmt.Errorf("%w: limit must be positive, got %d", ErrInvalidInput, opts.Limit)
+	}
+	if opts.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative, got %d", ErrInvalidInput, opts.Offset)
+	}
+	if opts.MinScore < 0 || opts.MinScore > 1 {
+		return fmt.Errorf("%w: min score must be between 0 and 1, got %g", ErrInvalidInput, opts.MinScore)
+	}
+	if opts.Diversity < 0 || opts.Diversity > 1 {
+		return fmt.Errorf("%w: diversity must be between 0 and 1, got %g", ErrInvalidInput, opts.Diversity)
+	}
+	if opts.MaxPerDoc < 0 {
+		return fmt.Errorf("%w: max per document must not be negative, got %d", ErrInvalidInput, opts.MaxPerDoc)
+	}
+	if opts.Context < 0 {
+		return fmt.Errorf("%w: context must not be negative, got %d", ErrInvalidInput, opts.Context)
+	}
+	switch opts.GroupBy {
+	case GroupByNone:
+	case GroupByFile:
+		if len(opts.KBs) > 1 || opts.Offset > 0 || opts.Diversity > 0 || opts.MaxPerDoc > 0 || opts.Context > 0 || opts.Expand != ExpandNone {
+			return fmt.Errorf("%w: grouping by file only works with a single knowledge base, limit and min score", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown group by %q, use %s", ErrInvalidInput, opts.GroupBy, GroupByFile)
+	}
+	switch opts.Expand {
+	case ExpandNone, ExpandParaphrase, ExpandHyDE:
+	default:
+		return fmt.Errorf("%w: unknown expand mode %q, use %s or %s", ErrInvalidInput, opts.Expand, ExpandParaphrase, ExpandHyDE)
+	}
+	for _, kb := range opts.KBs {
+		if err := ValidateKB(kb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchGroups finds the documents with the best matching chunks
+func searchGroups(storage *db.Storage, opts SearchOptions) (*SearchResults, error) {
+	embedding, err := queryEmbedding(storage, searchQuery{Text: opts.Query}, map[string][]float32{})
+	if err != nil {
+		return &SearchResults{
+			Success: false,
+			Error:   fmt.Sprintf("Search failed: %v", err),
+		}, err
+	}
+
+	groups, err := storage.SearchGroups(db.VectorQuery{
+		Vector:   embedding,
+		Limit:    opts.Limit,
+		MinScore: opts.MinScore,
+	}, chunksPerDocument)
 	if err != nil {
 		return &SearchResults{
 			Success: false,
@@ -28,16 +197,74 @@ func SearchFiles(searchTerm string, limit int) (*SearchResults, error) {
 		}, err
 	}
 
-	results := &SearchResults{
-		Success: true,
-		Query:   searchTerm,
-		Count:   len(searchResult),
-		Results: make([]SearchResult, 0, len(searchResult)),
+	documents := toDocumentGroups(groups)
+	return &SearchResults{
+		Success:   true,
+		Query:     opts.Query,
+		Results:   []SearchResult{},
+		Documents: documents,
+		Count:     len(documents),
+	}, nil
+}
+
+// searchKB runs the queries against one knowledge base, labelling the results with kb. The
+// rankings of several queries are fused into one.
+func searchKB(storage *db.Storage, kb string, queries []searchQuery, query db.VectorQuery, embeddings map[string][]float32) ([]SearchResult, error) {
+	rankings := make([][]SearchResult, 0, len(queries))
+	for _, text := range queries {
+		embedding, err := queryEmbedding(storage, text, embeddings)
+		if err != nil {
+			return nil, err
+		}
+
+		query.Vector = embedding
+		points, err := storage.SearchVector(query)
+		if err != nil {
+			return nil, err
+		}
+		rankings = append(rankings, toSearchResults(points, kb))
+	}
+
+	if len(rankings) == 1 {
+		return rankings[0], nil
+	}
+
+	fused := fuseRanks(rankings)
+	return fused[:min(len(fused), query.Limit)], nil
+}
+
+// queryEmbedding embeds a query with the prefix the collection was embedded with, reusing an
+// embedding of the same text. Hypothetical answers are embedded as documents.
+func queryEmbedding(storage *db.Storage, query searchQuery, embeddings map[string][]float32) ([]float32, error) {
+	prefixes, err := storage.RecordedPrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	text := prefixes.Query + query.Text
+	if query.AsDocument {
+		text = prefixes.Document + query.Text
 	}
 
-	for _, result := range searchResult {
+	if embedding, ok := embeddings[text]; ok {
+		return embedding, nil
+	}
+
+	embedding, err := storage.GetEmbedding(text)
+	if err != nil {
+		return nil, err
+	}
+	embeddings[text] = embedding
+	return embedding, nil
+}
+
+func toSearchResults(points []*qdrant.ScoredPoint, kb string) []SearchResult {
+	results := make([]SearchResult, 0, len(points))
+
+	for _, result := range points {
 		sr := SearchResult{
 			Score: result.Score,
+			KB:    kb,
 		}
 
 		if result.Payload != nil {
@@ -52,8 +279,50 @@ func SearchFiles(searchTerm string, limit int) (*SearchResults, error) {
 			}
 		}
 
-		results.Results = append(results.Results, sr)
+		if vector := result.GetVectors().GetVector(); vector != nil {
+			sr.vector = vector.GetDense().GetData()
+			if sr.vector == nil {
+				// Older Qdrant servers only fill the deprecated field
+				sr.vector = vector.GetData()
+			}
+		}
+
+		results = append(results, sr)
+	}
+
+	return results
+}
+
+// mergeResults combines results from several knowledge bases. Each score is divided by the best
+// score in its knowledge base, so a collection whose embeddings score higher overall cannot crowd
+// out the others; the original score is kept as RawScore.
+func mergeResults(perKB [][]SearchResult, limit int) []SearchResult {
+	merged := []SearchResult{}
+
+	for _, results := range perKB {
+		var best float32
+		for _, result := range results {
+			best = max(best, result.Score)
+		}
+
+		for _, result := range results {
+			result.RawScore = result.Score
+			if best > 0 {
+				result.Score = result.Score / best
+			}
+			merged = append(merged, result)
+		}
 	}
 
-	return results, nil
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Score != merged[j].Score {
+			return merged[i].Score > merged[j].Score
+		}
+		return merged[i].RawScore > merged[j].RawScore
+	})
+
+	if len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
 }
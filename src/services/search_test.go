@@ -0,0 +1,140 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeResults(t *testing.T) {
+	perKB := [][]SearchResult{
+		{
+			{KB: "wiki", Filename: "a.md", Score: 0.9},
+			{KB: "wiki", Filename: "b.md", Score: 0.45},
+		},
+		{
+			{KB: "emails", Filename: "c.txt", Score: 0.6},
+			{KB: "emails", Filename: "d.txt", Score: 0.5},
+		},
+	}
+
+	merged := mergeResults(perKB, 3)
+
+	expected := []struct {
+		filename string
+		score    float32
+		rawScore float32
+	}{
+		{"a.md", 1, 0.9},
+		{"c.txt", 1, 0.6},
+		{"d.txt", 0.5 / 0.6, 0.5},
+	}
+
+	if len(merged) != len(expected) {
+		t.Fatalf("mergeResults() returned %d results, want %d", len(merged), len(expected))
+	}
+
+	for i, want := range expected {
+		got := merged[i]
+		if got.Filename != want.filename || got.Score != want.score || got.RawScore != want.rawScore {
+			t.Errorf("result %d = %s (score %v, raw %v), want %s (score %v, raw %v)",
+				i, got.Filename, got.Score, got.RawScore, want.filename, want.score, want.rawScore)
+		}
+	}
+}
+
+func TestMergeResultsEmpty(t *testing.T) {
+	if merged := mergeResults([][]SearchResult{{}, {}}, 5); merged == nil || len(merged) != 0 {
+		t.Errorf("mergeResults() of empty lists = %v, want an empty slice", merged)
+	}
+}
+
+func filenames(results []SearchResult) []string {
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.Filename
+	}
+	return names
+}
+
+func TestDiversifyMaxPerDoc(t *testing.T) {
+	candidates := []SearchResult{
+		{Filename: "a.md", ChunkIndex: 0, Score: 0.9},
+		{Filename: "a.md", ChunkIndex: 1, Score: 0.89},
+		{Filename: "a.md", ChunkIndex: 2, Score: 0.88},
+		{Filename: "b.md", ChunkIndex: 0, Score: 0.7},
+		{Filename: "c.md", ChunkIndex: 0, Score: 0.6},
+	}
+
+	got := filenames(diversify(candidates, 3, 0, 1))
+	want := []string{"a.md", "b.md", "c.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("diversify() = %v, want %v", got, want)
+	}
+}
+
+func TestDiversifyMMR(t *testing.T) {
+	candidates := []SearchResult{
+		{Filename: "a.md", Score: 0.9, vector: []float32{1, 0}},
+		{Filename: "a-copy.md", Score: 0.89, vector: []float32{1, 0.01}},
+		{Filename: "b.md", Score: 0.7, vector: []float32{0, 1}},
+	}
+
+	if got, want := filenames(diversify(candidates, 2, 0, 0)), []string{"a.md", "a-copy.md"}; !slices.Equal(got, want) {
+		t.Errorf("diversify() without diversity = %v, want %v", got, want)
+	}
+	if got, want := filenames(diversify(candidates, 2, 0.5, 0)), []string{"a.md", "b.md"}; !slices.Equal(got, want) {
+		t.Errorf("diversify() with diversity = %v, want %v", got, want)
+	}
+}
+
+func TestDiversifyFusedResults(t *testing.T) {
+	a := SearchResult{Filename: "a.md", vector: []float32{1, 0}}
+	b := SearchResult{Filename: "a-copy.md", vector: []float32{0.99, 0.1}}
+	c := SearchResult{Filename: "c.md", vector: []float32{0.7, 0.7}}
+	d := SearchResult{Filename: "d.md", vector: []float32{0, 1}}
+
+	// Fused scores are about 0.016-0.033, far below the similarities they are weighed against
+	candidates := fuseRanks([][]SearchResult{{a, b, c}, {a, c, b, d}})
+
+	// d is the most dissimilar but ranks last in one list only, so it is not worth picking over c
+	if got, want := filenames(diversify(candidates, 2, 0.3, 0)), []string{"a.md", "c.md"}; !slices.Equal(got, want) {
+		t.Errorf("diversify() = %v, want %v", got, want)
+	}
+}
+
+func TestContextWindows(t *testing.T) {
+	results := []SearchResult{
+		{Filename: "a.md", ChunkIndex: 5, Score: 0.9},
+		{Filename: "b.md", ChunkIndex: 0, Score: 0.8},
+		{Filename: "a.md", ChunkIndex: 1, Score: 0.7},
+		{Filename: "a.md", ChunkIndex: 3, Score: 0.6},
+		{Filename: "a.md", ChunkIndex: 12, Score: 0.5},
+	}
+
+	windows := contextWindows(results, 1)
+
+	expected := []struct {
+		filename string
+		from, to int64
+		matched  []int64
+	}{
+		{"a.md", 0, 6, []int64{1, 3, 5}},
+		{"b.md", 0, 1, []int64{0}},
+		{"a.md", 11, 13, []int64{12}},
+	}
+
+	if len(windows) != len(expected) {
+		t.Fatalf("contextWindows() returned %d windows, want %d", len(windows), len(expected))
+	}
+
+	for i, want := range expected {
+		got := windows[i]
+		if got.Filename != want.filename || got.Context.From != want.from || got.Context.To != want.to || !slices.Equal(got.MatchedChunks, want.matched) {
+			t.Errorf("window %d = %s %d-%d %v, want %s %d-%d %v",
+				i, got.Filename, got.Context.From, got.Context.To, got.MatchedChunks, want.filename, want.from, want.to, want.matched)
+		}
+	}
+	if windows[0].Score != 0.9 {
+		t.Errorf("merged window score = %v, want the best hit's 0.9", windows[0].Score)
+	}
+}
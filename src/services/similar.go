@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/rhydianjenkins/seek/src/db"
+)
+
+// chunksPerDocument is how many matching chunks are returned for each document found
+const chunksPerDocument = 3
+
+// FindSimilar finds the documents most like a document, or one of its chunks, using the vectors
+// already stored for it. The source document is left out of the results.
+func FindSimilar(opts SimilarOptions) (*SimilarResults, error) {
+	if err := ValidateKB(opts.KB); err != nil {
+		return &SimilarResults{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+	if opts.Filename == "" {
+		return &SimilarResults{
+			Success: false,
+			Error:   "Filename cannot be empty",
+		}, fmt.Errorf("%w: filename cannot be empty", ErrInvalidInput)
+	}
+	if opts.Limit <= 0 {
+		return &SimilarResults{
+			Success: false,
+			Error:   fmt.Sprintf("Limit must be positive, got %d", opts.Limit),
+		}, fmt.Errorf("%w: limit must be positive, got %d", ErrInvalidInput, opts.Limit)
+	}
+
+	storage, err := db.ConnectTo(opts.KB)
+	if err != nil {
+		return &SimilarResults{
+			Success: false,
+			Error:   fmt.Sprintf("Unable to connect to storage: %v", err),
+		}, err
+	}
+
+	ids, err := storage.ChunkIDs(opts.Filename, opts.Chunk)
+	if err != nil {
+		return &SimilarResults{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to find %s: %v", opts.Filename, err),
+		}, err
+	}
+	if len(ids) == 0 {
+		source := opts.Filename
+		if opts.Chunk != nil {
+			source = fmt.Sprintf("chunk %d of %s", *opts.Chunk, opts.Filename)
+		}
+		return &SimilarResults{
+			Success: false,
+			Error:   fmt.Sprintf("No document found: %s", source),
+		}, fmt.Errorf("%w: %s", ErrNotFound, source)
+	}
+
+	groups, err := storage.SimilarGroups(ids, opts.Filename, opts.Limit, chunksPerDocument)
+	if err != nil {
+		return &SimilarResults{
+			Success: false,
+			Error:   fmt.Sprintf("Search failed: %v", err),
+		}, err
+	}
+
+	documents := toDocumentGroups(groups)
+	return &SimilarResults{
+		Success:   true,
+		Filename:  opts.Filename,
+		Chunk:     opts.Chunk,
+		Documents: documents,
+		Count:     len(documents),
+	}, nil
+}
+
+func toDocumentGroups(groups []*qdrant.PointGroup) []DocumentGroup {
+	documents := make([]DocumentGroup, 0, len(groups))
+	for _, group := range groups {
+		chunks := toSearchResults(group.Hits, "")
+		if len(chunks) == 0 {
+			continue
+		}
+
+		document := DocumentGroup{
+			Filename: group.Id.GetStringValue(),
+			Score:    chunks[0].Score,
+			Chunks:   chunks,
+		}
+		for _, chunk := range chunks {
+			document.TotalScore += chunk.Score
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
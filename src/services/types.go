@@ -1,7 +1,21 @@
 package services
 
+import (
+	"time"
+
+	"github.com/rhydianjenkins/seek/src/db"
+)
+
 type ProgressCallback func(current, total int, filename string)
 
+type sourceFile struct {
+	path     string
+	content  string
+	size     int64
+	fileType string
+	modTime  time.Time
+}
+
 type EmbedResult struct {
 	Success      bool   `json:"success"`
 	FilesIndexed int    `json:"files_indexed"`
@@ -10,19 +24,142 @@ type EmbedResult struct {
 	Error        string `json:"error,omitempty"`
 }
 
+// ExpandMode selects how a query is expanded into several before searching
+type ExpandMode string
+
+const (
+	ExpandNone ExpandMode = ""
+	// ExpandParaphrase searches for rewordings of the query as well
+	ExpandParaphrase ExpandMode = "paraphrase"
+	// ExpandHyDE searches for a hypothetical answer to the query as well
+	ExpandHyDE ExpandMode = "hyde"
+)
+
+// GroupBy selects how search results are grouped
+type GroupBy string
+
+const (
+	GroupByNone GroupBy = ""
+	// GroupByFile returns documents, each with its best matching chunks, instead of chunks
+	GroupByFile GroupBy = "file"
+)
+
+type SearchOptions struct {
+	Query  string
+	Limit  int
+	Offset int
+	// MinScore drops results whose similarity is below it, so fewer than Limit may be returned.
+	// It applies to raw scores, before they are normalised across knowledge bases.
+	MinScore float32
+	// KBs lists the knowledge bases to search; empty means the configured one. Scores from
+	// several knowledge bases are normalised before the results are merged.
+	KBs []string
+	// Diversity between 0 and 1 trades relevance for results that differ from each other, using
+	// Maximal Marginal Relevance; 0 ranks by relevance alone
+	Diversity float64
+	// MaxPerDoc caps the results taken from any one document; 0 means no cap
+	MaxPerDoc int
+	// Context adds this many neighbouring chunks either side of each result, merging results whose
+	// windows overlap in the same document
+	Context int
+	// Expand uses the chat model to search for related texts too, fusing the rankings of all of
+	// them; scores are then reciprocal rank fusion scores rather than similarities
+	Expand ExpandMode
+	// GroupBy returns documents instead of chunks, in Documents; Limit is then the number of
+	// documents
+	GroupBy GroupBy
+}
+
 type SearchResult struct {
 	Score      float32 `json:"score"`
+	RawScore   float32 `json:"raw_score,omitempty"`
+	KB         string  `json:"kb,omitempty"`
 	Filename   string  `json:"filename"`
 	ChunkIndex int64   `json:"chunk_index"`
 	Content    string  `json:"content"`
+	// Context is the range of chunks in Content when neighbouring chunks were added
+	Context *ChunkRange `json:"context,omitempty"`
+	// MatchedChunks lists the chunks within Context that matched the query
+	MatchedChunks []int64 `json:"matched_chunks,omitempty"`
+
+	// vector is only fetched when the results are diversified
+	vector []float32
+}
+
+type ChunkRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
 }
 
 type SearchResults struct {
-	Success bool           `json:"success"`
-	Query   string         `json:"query"`
-	Results []SearchResult `json:"results"`
-	Count   int            `json:"count"`
-	Error   string         `json:"error,omitempty"`
+	Success bool     `json:"success"`
+	Query   string   `json:"query"`
+	KBs     []string `json:"kbs,omitempty"`
+	// Expansions are the texts searched for alongside the query
+	Expansions []string       `json:"expansions,omitempty"`
+	Results    []SearchResult `json:"results"`
+	// Documents holds the results when they are grouped by file
+	Documents []DocumentGroup `json:"documents,omitempty"`
+	Count     int             `json:"count"`
+	Offset    int             `json:"offset,omitempty"`
+	// NextOffset is the offset of the next page, when there are more results
+	NextOffset int    `json:"next_offset,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type SimilarOptions struct {
+	KB       string
+	Filename string
+	// Chunk limits the source to one chunk of the document; nil uses the whole document
+	Chunk *int64
+	Limit int
+}
+
+// DocumentGroup is a document found by a search, with its best matching chunks
+type DocumentGroup struct {
+	Filename string `json:"filename"`
+	// Score is the best chunk's score, which documents are ranked by
+	Score float32 `json:"score"`
+	// TotalScore adds up the scores of Chunks, so documents with several good matches stand out
+	TotalScore float32        `json:"total_score"`
+	Chunks     []SearchResult `json:"chunks"`
+}
+
+type SimilarResults struct {
+	Success   bool            `json:"success"`
+	Filename  string          `json:"filename"`
+	Chunk     *int64          `json:"chunk,omitempty"`
+	Documents []DocumentGroup `json:"documents"`
+	Count     int             `json:"count"`
+	Error     string          `json:"error,omitempty"`
+}
+
+type GrepOptions struct {
+	KB      string
+	Pattern string
+	// IgnoreCase and Word work like grep's -i and -w
+	IgnoreCase bool
+	Word       bool
+	// Limit caps the number of matching lines returned
+	Limit int
+}
+
+type GrepMatch struct {
+	Filename   string `json:"filename"`
+	ChunkIndex int64  `json:"chunk_index"`
+	// Line is the number of the matching line within the chunk, from 1
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+type GrepResults struct {
+	Success bool        `json:"success"`
+	Pattern string      `json:"pattern"`
+	Matches []GrepMatch `json:"matches"`
+	Count   int         `json:"count"`
+	// Truncated is set when there were more matches than the limit
+	Truncated bool   `json:"truncated,omitempty"`
+	Error     string `json:"error,omitempty"`
 }
 
 type DocumentChunk struct {
@@ -37,4 +174,77 @@ type DocumentResult struct {
 	Chunks     []DocumentChunk `json:"chunks"`
 	FullText   string          `json:"full_text"`
 	Error      string          `json:"error,omitempty"`
+	// Suggestions are the closest filenames when no document has the one asked for
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+type DocumentOptions struct {
+	KB       string
+	Filename string
+	// From and To limit the document to a range of chunk indexes, inclusive
+	From *int64
+	To   *int64
+}
+
+type Citation struct {
+	Number     int     `json:"number"`
+	Filename   string  `json:"filename"`
+	ChunkIndex int64   `json:"chunk_index"`
+	Score      float32 `json:"score,omitempty"`
+	Content    string  `json:"content,omitempty"`
+}
+
+type AskResult struct {
+	Question         string     `json:"question"`
+	Answer           string     `json:"answer"`
+	Citations        []Citation `json:"citations"`
+	InvalidCitations []int      `json:"invalid_citations,omitempty"`
+}
+
+// DocumentSort orders listed documents
+type DocumentSort string
+
+const (
+	// SortByName lists documents by filename, which is the default
+	SortByName DocumentSort = "name"
+	// SortBySize, SortByChunks, SortByModified and SortByIndexed list the largest or newest first
+	SortBySize     DocumentSort = "size"
+	SortByChunks   DocumentSort = "chunks"
+	SortByModified DocumentSort = "modified"
+	SortByIndexed  DocumentSort = "indexed"
+)
+
+type ListOptions struct {
+	KB      string
+	Prefix  string
+	Pattern string
+	// Type only lists documents of this file type, e.g. pdf
+	Type string
+	Sort DocumentSort
+	// Reverse flips the order Sort gives
+	Reverse bool
+	Offset  int
+	Limit   int
+}
+
+type DocumentList struct {
+	Success    bool              `json:"success"`
+	Documents  []db.DocumentInfo `json:"documents"`
+	Total      int               `json:"total"`
+	Offset     int               `json:"offset"`
+	NextOffset int               `json:"next_offset,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type KBInfo struct {
+	Name      string `json:"name"`
+	Points    uint64 `json:"points"`
+	IndexedAt string `json:"indexed_at,omitempty"`
+	Default   bool   `json:"default"`
+}
+
+type KBList struct {
+	Success        bool     `json:"success"`
+	KnowledgeBases []KBInfo `json:"knowledge_bases"`
+	Error          string   `json:"error,omitempty"`
 }
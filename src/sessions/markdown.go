@@ -0,0 +1,65 @@
+package sessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExportMarkdown renders a session as a Markdown transcript, including tool calls and their results
+func ExportMarkdown(session *Session) string {
+	var md strings.Builder
+
+	fmt.Fprintf(&md, "# Session: %s\n\n", session.Name)
+	fmt.Fprintf(&md, "_Started %s, last updated %s_\n",
+		session.CreatedAt.Format("2006-01-02 15:04"),
+		session.UpdatedAt.Format("2006-01-02 15:04"))
+
+	for _, message := range session.Messages {
+		switch message.Role {
+		case "user":
+			fmt.Fprintf(&md, "\n## User\n\n%s\n", strings.TrimSpace(message.Content))
+
+		case "assistant":
+			if content := strings.TrimSpace(message.Content); content != "" {
+				fmt.Fprintf(&md, "\n## Assistant\n\n%s\n", content)
+			}
+			for _, toolCall := range message.ToolCalls {
+				fmt.Fprintf(&md, "\n> Tool call: `%s` %s\n", toolCall.Function.Name, formatArguments(toolCall.Function.Arguments))
+			}
+
+		case "tool":
+			fmt.Fprintf(&md, "\n<details>\n<summary>Tool result</summary>\n\n```\n%s\n```\n\n</details>\n", strings.TrimSpace(message.Content))
+		}
+	}
+
+	if len(session.Sources) > 0 {
+		md.WriteString("\n## Sources\n\n")
+		for _, source := range session.Sources {
+			fmt.Fprintf(&md, "- [%d] %s (chunk %d)\n", source.Number, source.Filename, source.ChunkIndex)
+		}
+	}
+
+	return md.String()
+}
+
+func formatArguments(raw json.RawMessage) string {
+	var args map[string]any
+	if err := json.Unmarshal(raw, &args); err != nil || len(args) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s: %v", key, args[key]))
+	}
+
+	return "(" + strings.Join(pairs, ", ") + ")"
+}
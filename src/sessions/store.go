@@ -0,0 +1,152 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("session not found")
+	ErrInvalidName = errors.New("invalid session name")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Open returns the store in $XDG_DATA_HOME/seek/sessions (~/.local/share/seek/sessions by default)
+func Open() (*Store, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to locate home directory: %w", err)
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+
+	return NewStore(filepath.Join(dataDir, "seek", "sessions")), nil
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("%w %q: use letters, digits, '.', '_' or '-'", ErrInvalidName, name)
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
+
+func (s *Store) Load(name string) (*Session, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", name, err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", name, err)
+	}
+
+	return &session, nil
+}
+
+// LoadOrCreate loads the named session, starting an empty one if it does not exist yet
+func (s *Store) LoadOrCreate(name string) (*Session, error) {
+	session, err := s.Load(name)
+	if errors.Is(err, ErrNotFound) {
+		now := time.Now()
+		return &Session{Name: name, CreatedAt: now, UpdatedAt: now}, nil
+	}
+	return session, err
+}
+
+func (s *Store) Save(session *Session) error {
+	path, err := s.path(session.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	session.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	// Write to a temporary file first so an interrupted save never corrupts the session
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session %s: %w", session.Name, err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *Store) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return err
+}
+
+// List returns a summary of every stored session, most recently updated first
+func (s *Store) List() ([]Summary, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Summary{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+
+	summaries := make([]Summary, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		session, err := s.Load(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+
+		summaries = append(summaries, Summary{
+			Name:         session.Name,
+			CreatedAt:    session.CreatedAt,
+			UpdatedAt:    session.UpdatedAt,
+			MessageCount: len(session.Messages),
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+	})
+
+	return summaries, nil
+}
@@ -0,0 +1,74 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rhydianjenkins/seek/src/ollama"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	session, err := store.LoadOrCreate("design-review")
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error = %v", err)
+	}
+
+	session.Messages = append(session.Messages,
+		ollama.Message{Role: "user", Content: "How does auth work?"},
+		ollama.Message{Role: "assistant", ToolCalls: []ollama.ToolCall{{
+			Function: ollama.FunctionCall{Name: "search", Arguments: json.RawMessage(`{"query":"auth"}`)},
+		}}},
+		ollama.Message{Role: "tool", Content: "[1] auth.md (chunk 0, score 0.9000)\nTokens."},
+		ollama.Message{Role: "assistant", Content: "It uses tokens [1]."},
+	)
+
+	if err := store.Save(session); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := store.Load("design-review")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(loaded.Messages) != 4 || loaded.Messages[1].ToolCalls[0].Function.Name != "search" {
+		t.Errorf("Load() messages = %+v, want the saved history", loaded.Messages)
+	}
+
+	summaries, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Name != "design-review" || summaries[0].MessageCount != 4 {
+		t.Errorf("List() = %+v, want one summary with 4 messages", summaries)
+	}
+
+	markdown := ExportMarkdown(loaded)
+	for _, expected := range []string{"# Session: design-review", "## User", "Tool call: `search` (query: auth)", "It uses tokens [1]."} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("ExportMarkdown() does not contain %q", expected)
+		}
+	}
+
+	if err := store.Delete("design-review"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := store.Load("design-review"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreRejectsInvalidNames(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for _, name := range []string{"", "../escape", "a/b", ".hidden"} {
+		if _, err := store.Load(name); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Load(%q) error = %v, want invalid name error", name, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package sessions
+
+import (
+	"time"
+
+	"github.com/rhydianjenkins/seek/src/ollama"
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+type Session struct {
+	Name      string              `json:"name"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	Messages  []ollama.Message    `json:"messages"`
+	Sources   []services.Citation `json:"sources,omitempty"`
+}
+
+type Summary struct {
+	Name         string    `json:"name"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	MessageCount int       `json:"message_count"`
+}
+
+type Store struct {
+	dir string
+}
@@ -3,12 +3,14 @@ package tools
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/rhydianjenkins/seek/src/ollama"
 	"github.com/rhydianjenkins/seek/src/services"
 )
 
-func ExecuteTool(toolCall ollama.ToolCall) (string, error) {
+func ExecuteTool(toolCall ollama.ToolCall, sources *Sources) (string, error) {
 	switch toolCall.Function.Name {
 	case "search":
 		// Parse flexibly - Ollama may send limit as string or int
@@ -19,52 +21,137 @@ func ExecuteTool(toolCall ollama.ToolCall) (string, error) {
 
 		query, _ := rawInput["query"].(string)
 
-		// Handle limit as either int or string
-		limit := 3 // default
-		if limitVal, ok := rawInput["limit"]; ok && limitVal != nil {
-			switch v := limitVal.(type) {
-			case float64: // JSON numbers are float64
-				limit = int(v)
-			case string:
-				if parsedLimit, err := json.Number(v).Int64(); err == nil {
-					limit = int(parsedLimit)
-				}
-			case int:
-				limit = v
-			}
-		}
-
-		results, err := services.SearchFiles(query, limit)
+		results, err := services.Search(services.SearchOptions{
+			Query:     query,
+			Limit:     intArg(rawInput, "limit", 3),
+			Offset:    intArg(rawInput, "offset", 0),
+			MinScore:  float32(floatArg(rawInput, "min_score", 0)),
+			Diversity: floatArg(rawInput, "diversity", 0),
+			MaxPerDoc: intArg(rawInput, "max_per_doc", 0),
+			Context:   intArg(rawInput, "context", 0),
+		})
 		if err != nil {
 			return "", fmt.Errorf("search failed: %w", err)
 		}
 
-		resultJSON, err := json.Marshal(results)
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal search results: %w", err)
+		if len(results.Results) == 0 {
+			return fmt.Sprintf("No results found for %q.", query), nil
 		}
-		return string(resultJSON), nil
 
-	case "get_document":
-		var input struct {
-			Filename string `json:"filename"`
+		var output strings.Builder
+		for _, result := range results.Results {
+			number := sources.Add(result.Filename, result.ChunkIndex, result.Score, result.Content)
+			chunks := fmt.Sprintf("chunk %d", result.ChunkIndex)
+			if result.Context != nil {
+				chunks = fmt.Sprintf("chunks %d-%d", result.Context.From, result.Context.To)
+			}
+			fmt.Fprintf(&output, "[%d] %s (%s, score %.4f)\n%s\n\n", number, result.Filename, chunks, result.Score, result.Content)
+		}
+		if results.NextOffset > 0 {
+			fmt.Fprintf(&output, "More results are available with offset %d.", results.NextOffset)
 		}
-		if err := json.Unmarshal(toolCall.Function.Arguments, &input); err != nil {
+		return strings.TrimSpace(output.String()), nil
+
+	case "get_document":
+		var rawInput map[string]any
+		if err := json.Unmarshal(toolCall.Function.Arguments, &rawInput); err != nil {
 			return "", fmt.Errorf("failed to parse get_document arguments: %w", err)
 		}
 
-		result, err := services.GetDocumentByFilename(input.Filename)
+		filename, _ := rawInput["filename"].(string)
+		opts := services.DocumentOptions{Filename: filename}
+		if from := int64(intArg(rawInput, "from", -1)); from >= 0 {
+			opts.From = &from
+		}
+		if to := int64(intArg(rawInput, "to", -1)); to >= 0 {
+			opts.To = &to
+		}
+
+		result, err := services.GetDocument(opts)
 		if err != nil {
 			return "", fmt.Errorf("get_document failed: %w", err)
 		}
 
-		resultJSON, err := json.Marshal(result)
+		var output strings.Builder
+		for _, chunk := range result.Chunks {
+			number := sources.Add(result.Filename, chunk.ChunkIndex, 0, chunk.Content)
+			fmt.Fprintf(&output, "[%d] %s (chunk %d)\n%s\n\n", number, result.Filename, chunk.ChunkIndex, chunk.Content)
+		}
+		return strings.TrimSpace(output.String()), nil
+
+	case "list_documents":
+		var rawInput map[string]any
+		if err := json.Unmarshal(toolCall.Function.Arguments, &rawInput); err != nil {
+			return "", fmt.Errorf("failed to parse list_documents arguments: %w", err)
+		}
+
+		prefix, _ := rawInput["prefix"].(string)
+		pattern, _ := rawInput["pattern"].(string)
+		fileType, _ := rawInput["type"].(string)
+		sortBy, _ := rawInput["sort"].(string)
+
+		result, err := services.ListDocuments(services.ListOptions{
+			Prefix:  prefix,
+			Pattern: pattern,
+			Type:    fileType,
+			Sort:    services.DocumentSort(sortBy),
+			Offset:  intArg(rawInput, "offset", 0),
+			Limit:   intArg(rawInput, "limit", services.DefaultListLimit),
+		})
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal document result: %w", err)
+			return "", fmt.Errorf("list_documents failed: %w", err)
+		}
+
+		if len(result.Documents) == 0 {
+			return "No documents found.", nil
+		}
+
+		var output strings.Builder
+		fmt.Fprintf(&output, "%d documents (showing %d from offset %d):\n", result.Total, len(result.Documents), result.Offset)
+		for _, document := range result.Documents {
+			fmt.Fprintf(&output, "- %s (%d chunks, %d bytes, %s, modified %s)\n", document.Filename, document.ChunkCount, document.Size, document.FileType, document.ModifiedAt)
+		}
+		if result.NextOffset > 0 {
+			fmt.Fprintf(&output, "More documents are available with offset %d.", result.NextOffset)
 		}
-		return string(resultJSON), nil
+		return strings.TrimSpace(output.String()), nil
 
 	default:
 		return "", fmt.Errorf("unknown tool: %s", toolCall.Function.Name)
 	}
 }
+
+// intArg reads an integer argument flexibly - Ollama may send numbers as strings
+func intArg(args map[string]any, key string, defaultValue int) int {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return defaultValue
+	}
+
+	switch v := value.(type) {
+	case float64: // JSON numbers are float64
+		return int(v)
+	case string:
+		if parsed, err := json.Number(v).Int64(); err == nil {
+			return int(parsed)
+		}
+	case int:
+		return v
+	}
+
+	return defaultValue
+}
+
+// floatArg reads a number argument flexibly, like intArg
+func floatArg(args map[string]any, key string, defaultValue float64) float64 {
+	switch v := args[key].(type) {
+	case float64:
+		return v
+	case string:
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			return parsed
+		}
+	}
+
+	return defaultValue
+}
@@ -1,6 +1,11 @@
 package tools
 
-import "github.com/rhydianjenkins/seek/src/ollama"
+import (
+	"fmt"
+
+	"github.com/rhydianjenkins/seek/src/ollama"
+	"github.com/rhydianjenkins/seek/src/services"
+)
 
 func GetTools() []ollama.Tool {
 	return []ollama.Tool{
@@ -8,7 +13,7 @@ func GetTools() []ollama.Tool {
 			Type: "function",
 			Function: ollama.FunctionDef{
 				Name:        "search",
-				Description: "Search the RAG knowledge base for relevant content using semantic similarity. Use this to find information related to the user's question.",
+				Description: "Search the RAG knowledge base for relevant content using semantic similarity. Use this to find information related to the user's question. Each result is numbered so it can be cited as [n].",
 				Parameters: map[string]any{
 					"type": "object",
 					"properties": map[string]any{
@@ -20,6 +25,26 @@ func GetTools() []ollama.Tool {
 							"type":        "integer",
 							"description": "Maximum number of search results to return (default: 3)",
 						},
+						"offset": map[string]any{
+							"type":        "integer",
+							"description": "Number of results to skip, to see more results for the same query (default: 0)",
+						},
+						"min_score": map[string]any{
+							"type":        "number",
+							"description": "Drop results whose similarity score is below this, between 0 and 1 (default: 0)",
+						},
+						"diversity": map[string]any{
+							"type":        "number",
+							"description": "Between 0 and 1: prefer results that differ from each other, e.g. 0.3 for a broad question (default: 0)",
+						},
+						"max_per_doc": map[string]any{
+							"type":        "integer",
+							"description": "Maximum number of results from any one document (default: no cap)",
+						},
+						"context": map[string]any{
+							"type":        "integer",
+							"description": "Include this many neighbouring chunks either side of each result, e.g. 1 when a result is cut off (default: 0)",
+						},
 					},
 					"required": []string{"query"},
 				},
@@ -29,7 +54,7 @@ func GetTools() []ollama.Tool {
 			Type: "function",
 			Function: ollama.FunctionDef{
 				Name:        "get_document",
-				Description: "Retrieve a full document by filename, returning all chunks in order. Use this when you need the complete content of a specific document.",
+				Description: "Retrieve a full document by filename, returning all chunks in order. Use this when you need the complete content of a specific document; for long documents, pass from and to to read a range of chunks. Each chunk is numbered so it can be cited as [n].",
 				Parameters: map[string]any{
 					"type": "object",
 					"properties": map[string]any{
@@ -37,10 +62,55 @@ func GetTools() []ollama.Tool {
 							"type":        "string",
 							"description": "The name of the file to retrieve",
 						},
+						"from": map[string]any{
+							"type":        "integer",
+							"description": "First chunk index to return (default: the first chunk)",
+						},
+						"to": map[string]any{
+							"type":        "integer",
+							"description": "Last chunk index to return, inclusive (default: the last chunk)",
+						},
 					},
 					"required": []string{"filename"},
 				},
 			},
 		},
+		{
+			Type: "function",
+			Function: ollama.FunctionDef{
+				Name:        "list_documents",
+				Description: "List the documents in the knowledge base with their chunk count, size, type and modification time. Use this to find exact filenames before calling get_document.",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"prefix": map[string]any{
+							"type":        "string",
+							"description": "Only list documents whose filename starts with this prefix",
+						},
+						"pattern": map[string]any{
+							"type":        "string",
+							"description": "Only list documents matching this glob, e.g. *.pdf or docs/*.md",
+						},
+						"type": map[string]any{
+							"type":        "string",
+							"description": "Only list documents of this file type, e.g. pdf or md",
+						},
+						"sort": map[string]any{
+							"type":        "string",
+							"enum":        []string{"name", "size", "chunks", "modified", "indexed"},
+							"description": "Sort by name (default), or list the largest or newest documents first",
+						},
+						"offset": map[string]any{
+							"type":        "integer",
+							"description": "Number of documents to skip, for paging (default: 0)",
+						},
+						"limit": map[string]any{
+							"type":        "integer",
+							"description": fmt.Sprintf("Maximum number of documents to return (default: %d)", services.DefaultListLimit),
+						},
+					},
+				},
+			},
+		},
 	}
 }
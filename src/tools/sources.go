@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rhydianjenkins/seek/src/services"
+)
+
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// Sources numbers the chunks retrieved during a conversation so the model can cite them
+type Sources struct {
+	citations []services.Citation
+	numbers   map[string]int
+}
+
+func NewSources() *Sources {
+	return &Sources{
+		numbers: make(map[string]int),
+	}
+}
+
+// NewSourcesFrom restores the numbering of a previous conversation so resumed answers keep citing the same numbers
+func NewSourcesFrom(citations []services.Citation) *Sources {
+	sources := NewSources()
+	for _, citation := range citations {
+		sources.Add(citation.Filename, citation.ChunkIndex, citation.Score, citation.Content)
+	}
+	return sources
+}
+
+// Add registers a chunk and returns its citation number, reusing the number if the chunk was seen before
+func (s *Sources) Add(filename string, chunkIndex int64, score float32, content string) int {
+	key := fmt.Sprintf("%s#%d", filename, chunkIndex)
+	if number, ok := s.numbers[key]; ok {
+		if score > s.citations[number-1].Score {
+			s.citations[number-1].Score = score
+		}
+		return number
+	}
+
+	number := len(s.citations) + 1
+	s.numbers[key] = number
+	s.citations = append(s.citations, services.Citation{
+		Number:     number,
+		Filename:   filename,
+		ChunkIndex: chunkIndex,
+		Score:      score,
+		Content:    content,
+	})
+
+	return number
+}
+
+func (s *Sources) All() []services.Citation {
+	return s.citations
+}
+
+// Resolve splits the citation numbers found in an answer into known sources and unknown numbers
+func (s *Sources) Resolve(answer string) ([]services.Citation, []int) {
+	var cited []services.Citation
+	var invalid []int
+
+	for _, number := range ParseCitations(answer) {
+		if number < 1 || number > len(s.citations) {
+			invalid = append(invalid, number)
+			continue
+		}
+		cited = append(cited, s.citations[number-1])
+	}
+
+	return cited, invalid
+}
+
+// ParseCitations returns the unique citation numbers referenced in text as [1] or [1, 2], in ascending order
+func ParseCitations(text string) []int {
+	seen := make(map[int]bool)
+	var numbers []int
+
+	for _, match := range citationPattern.FindAllStringSubmatch(text, -1) {
+		for _, part := range strings.Split(match[1], ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || seen[number] {
+				continue
+			}
+			seen[number] = true
+			numbers = append(numbers, number)
+		}
+	}
+
+	sort.Ints(numbers)
+	return numbers
+}
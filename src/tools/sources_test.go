@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCitations(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []int
+	}{
+		{"no citations", "Nothing to see here.", nil},
+		{"single citation", "The sky is blue [1].", []int{1}},
+		{"adjacent citations", "Both agree [2][1].", []int{1, 2}},
+		{"comma separated", "See [3, 1] for details.", []int{1, 3}},
+		{"duplicates", "First [1], again [1].", []int{1}},
+		{"ignores non-numeric brackets", "A [link] and [1a] but [4].", []int{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseCitations(tt.text)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseCitations(%q) = %v, want %v", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSourcesResolve(t *testing.T) {
+	sources := NewSources()
+
+	first := sources.Add("a.txt", 0, 0.5, "alpha")
+	second := sources.Add("b.txt", 2, 0.7, "beta")
+	again := sources.Add("a.txt", 0, 0.9, "alpha")
+
+	if first != 1 || second != 2 || again != 1 {
+		t.Fatalf("Add() numbers = %d, %d, %d, want 1, 2, 1", first, second, again)
+	}
+
+	if score := sources.All()[0].Score; score != 0.9 {
+		t.Errorf("repeated Add() kept score %v, want 0.9", score)
+	}
+
+	cited, invalid := sources.Resolve("Alpha [1], beta [2], gamma [5].")
+
+	if len(cited) != 2 || cited[0].Filename != "a.txt" || cited[1].Filename != "b.txt" {
+		t.Errorf("Resolve() cited = %+v, want a.txt and b.txt", cited)
+	}
+
+	if !reflect.DeepEqual(invalid, []int{5}) {
+		t.Errorf("Resolve() invalid = %v, want [5]", invalid)
+	}
+}